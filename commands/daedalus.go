@@ -16,8 +16,12 @@
 package commands
 
 import (
-	"errors"
 	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
 	"math/rand"
 	"net/http"
 	"os"
@@ -25,28 +29,41 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/golangchallenge/gc6/commands/generators"
 	"github.com/golangchallenge/gc6/mazelib"
+	"github.com/golangchallenge/gc6/solver"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
-	"math"
 )
 
-type Maze struct {
-	rooms      [][]mazelib.Room
-	start      mazelib.Coordinate
-	end        mazelib.Coordinate
-	icarus     mazelib.Coordinate
-	StepsTaken int
+// dumpCmd writes a freshly generated maze out as a text fixture instead of
+// serving it, e.g. `daedalus dump --generator=prim --out=maze.txt`. Handy
+// for building a corpus of known mazes to regression-test generators and
+// Icarus solvers against.
+var dumpCmd = &cobra.Command{
+	Use:   "dump",
+	Short: "Generate a maze and write it out as a text fixture",
+	Run: func(cmd *cobra.Command, args []string) {
+		runDump()
+	},
 }
 
-// Tracking the current maze being solved
+// Maze is an alias for mazelib.Maze. The type itself lives in mazelib now
+// so that generators, loaders and solvers can all build/walk one without
+// importing commands.
+type Maze = mazelib.Maze
+
+// sessions holds one maze per connected Icarus client, so the server can
+// run a tournament of many clients at once instead of only a single one.
+var sessions = NewSessionManager(30 * time.Minute)
 
-// WARNING: This approach is not safe for concurrent use
-// This server is only intended to have a single client at a time
-// We would need a different and more complex approach if we wanted
-// concurrent connections than these simple package variables
-var currentMaze *Maze
-var scores []int
+// scores is the process-wide tally across every session, reported by
+// printResults.
+var scores = &scoreBoard{}
+
+// mazeRand is the single RNG threaded through maze generation. It is seeded
+// once, from --seed if given, so that a run can be reproduced exactly.
+var mazeRand *rand.Rand
 
 // Defining the daedalus command.
 // This will be called as 'laybrinth daedalus'
@@ -67,6 +84,30 @@ func init() {
 	rand.Seed(time.Now().UTC().UnixNano()) // need to initialize the seed
 	gin.SetMode(gin.ReleaseMode)
 
+	daedalusCmd.Flags().Int64("seed", 0, "seed for the maze generator's RNG, 0 picks a random one")
+	viper.BindPFlag("seed", daedalusCmd.Flags().Lookup("seed"))
+
+	daedalusCmd.Flags().String("maze-file", "", "load a maze from a text fixture (see 'daedalus dump') instead of generating one")
+	viper.BindPFlag("maze-file", daedalusCmd.Flags().Lookup("maze-file"))
+
+	daedalusCmd.Flags().String("mazefile", "", "with --maze=file, a hand-drawn ASCII maze to load instead of generating one")
+	viper.BindPFlag("mazefile", daedalusCmd.Flags().Lookup("mazefile"))
+
+	daedalusCmd.Flags().String("solve", "", "run a solver (bfs, dijkstra, astar) against each maze as it's built and print its solution length")
+	viper.BindPFlag("solve", daedalusCmd.Flags().Lookup("solve"))
+
+	daedalusCmd.Flags().Int("pois", 0, "scatter N waypoints (Reply.POI) across the maze that a multi-stop IA should visit before the treasure")
+	viper.BindPFlag("pois", daedalusCmd.Flags().Lookup("pois"))
+
+	daedalusCmd.Flags().Int("portals", 0, "insert N random portal pairs after generation (portal generator only, 0 keeps its own default pair count)")
+	viper.BindPFlag("portals", daedalusCmd.Flags().Lookup("portals"))
+
+	dumpCmd.Flags().String("generator", "", "generator to use, defaults to the same selection buildMaze() would make")
+	viper.BindPFlag("generator", dumpCmd.Flags().Lookup("generator"))
+	dumpCmd.Flags().String("out", "maze.txt", "file to write the generated maze to")
+	viper.BindPFlag("out", dumpCmd.Flags().Lookup("out"))
+	daedalusCmd.AddCommand(dumpCmd)
+
 	// Removed some commands from here
 	RootCmd.AddCommand(daedalusCmd)
 }
@@ -90,52 +131,68 @@ func RunServer() {
 		v1.GET("/awake", GetStartingPoint)
 		v1.GET("/move/:direction", MoveDirection)
 		v1.GET("/done", End)
+		v1.GET("/stats/:session", SessionStats)
 	}
 
 	r.Run(":" + viper.GetString("port"))
 }
 
-// Ends a session and prints the results.
-// Called by Icarus when he has reached
-//   the number of times he wants to solve the laybrinth.
+// Ends a session, retiring its maze, and reports the running average across
+// every session so far. Called by Icarus when he has reached the number of
+// times he wants to solve the laybrinth; unlike a single-client run, the
+// server has many sessions potentially in flight at once, so this only ever
+// resolves the caller's own session (as MoveDirection does) and leaves
+// everyone else's maze - and the server itself - running.
 func End(c *gin.Context) {
+	sessions.End(sessionIDFromRequest(c))
 	printResults()
-	os.Exit(1)
+	c.JSON(http.StatusOK, mazelib.Reply{Message: "session ended"})
 }
 
-// initializes a new maze and places Icarus in his awakening location
+// initializes a new maze, places Icarus in his awakening location, and
+// hands the client back a session ID (as both a cookie and a JSON field)
+// that ties every later call to this particular maze.
 func GetStartingPoint(c *gin.Context) {
-	initializeMaze()
-	startRoom, err := currentMaze.Discover(currentMaze.Icarus())
+	m := buildMaze()
+	startRoom, err := m.Discover(m.Icarus())
 	if err != nil {
 		fmt.Println("Icarus is outside of the maze. This shouldn't ever happen")
 		fmt.Println(err)
 		os.Exit(-1)
 	}
-	mazelib.PrintMaze(currentMaze)
+	mazelib.PrintMaze(m)
+	reportSolve(m)
+
+	id := sessions.New(m)
+	c.SetCookie(sessionCookie, id, 0, "/", "", false, true)
 
-	c.JSON(http.StatusOK, mazelib.Reply{Survey: startRoom})
+	ix, iy := m.Icarus()
+	room, _ := m.GetRoom(ix, iy)
+
+	c.JSON(http.StatusOK, mazelib.Reply{Survey: startRoom, SessionID: id, POI: room.POI})
 }
 
 // The API response to the /move/:direction address
 func MoveDirection(c *gin.Context) {
+	m, ok := sessions.Get(sessionIDFromRequest(c))
+	if !ok {
+		c.JSON(http.StatusNotFound, mazelib.Reply{Error: true, Message: "unknown or expired session"})
+		return
+	}
+
 	var err error
 
 	switch c.Param("direction") {
 	case "left":
-		err = currentMaze.MoveLeft()
+		err = m.MoveLeft()
 	case "right":
-		err = currentMaze.MoveRight()
+		err = m.MoveRight()
 	case "down":
-		err = currentMaze.MoveDown()
+		err = m.MoveDown()
 	case "up":
-		err = currentMaze.MoveUp()
+		err = m.MoveUp()
 	}
 
-	/*ix, iy := currentMaze.Icarus()
-	currentMaze.SetStartPoint(ix, iy)
-	mazelib.PrintMaze(currentMaze)*/
-
 	var r mazelib.Reply
 
 	if err != nil {
@@ -145,1021 +202,313 @@ func MoveDirection(c *gin.Context) {
 		return
 	}
 
-	s, e := currentMaze.LookAround()
+	s, e := m.LookAround()
 
 	if e != nil {
 		if e == mazelib.ErrVictory {
-			scores = append(scores, currentMaze.StepsTaken)
+			id := sessionIDFromRequest(c)
+			sessions.RecordScore(id, m.StepsTaken, m.Energy)
+			scores.record(m.StepsTaken, m.Energy)
 			r.Victory = true
-			r.Message = fmt.Sprintf("Victory achieved in %d steps \n", currentMaze.StepsTaken)
+			r.Message = fmt.Sprintf("Victory achieved in %d steps \n", m.StepsTaken)
+			if m.Optimal > 0 {
+				r.Message += fmt.Sprintf("Spent %d energy (optimal was %d)\n", m.Energy, m.Optimal)
+			}
 		} else {
 			r.Error = true
 			r.Message = err.Error()
 		}
 	}
 
-	r.Survey = s
-
-	c.JSON(http.StatusOK, r)
-}
-
-func initializeMaze() {
-	currentMaze = createMaze()
-}
-
-// Print to the terminal the average steps to solution for the current session
-func printResults() {
-	fmt.Printf("Labyrinth solved %d times with an avg of %d steps\n", len(scores), mazelib.AvgScores(scores))
-}
-
-// Return a room from the maze
-func (m *Maze) GetRoom(x, y int) (*mazelib.Room, error) {
-	if x < 0 || y < 0 || x >= m.Width() || y >= m.Height() {
-		return &mazelib.Room{}, errors.New("room outside of maze boundaries")
-	}
-
-	return &m.rooms[y][x], nil
-}
-
-func (m *Maze) Width() int  { return len(m.rooms[0]) }
-func (m *Maze) Height() int { return len(m.rooms) }
-
-// Return Icarus's current position
-func (m *Maze) Icarus() (x, y int) {
-	return m.icarus.X, m.icarus.Y
-}
-
-// Set the location where Icarus will awake
-func (m *Maze) SetStartPoint(x, y int) error {
-	r, err := m.GetRoom(x, y)
-
-	if err != nil {
-		return err
-	}
-
-	if r.Treasure {
-		return errors.New("can't start in the treasure")
-	}
-
-	r.Start = true
-	m.icarus = mazelib.Coordinate{x, y}
-	return nil
-}
-
-// Set the location of the treasure for a given maze
-func (m *Maze) SetTreasure(x, y int) error {
-	r, err := m.GetRoom(x, y)
-
-	if err != nil {
-		return err
+	ix, iy := m.Icarus()
+	if room, roomErr := m.GetRoom(ix, iy); roomErr == nil {
+		r.POI = room.POI
 	}
 
-	if r.Start {
-		return errors.New("can't have the treasure at the start")
-	}
+	r.Survey = s
+	r.Teleported = m.LastTeleport()
+	r.StepsTaken = m.StepsTaken
+	r.Energy = m.Energy
 
-	r.Treasure = true
-	m.end = mazelib.Coordinate{x, y}
-	return nil
+	c.JSON(http.StatusOK, r)
 }
 
-// Given Icarus's current location, Discover that room
-// Will return ErrVictory if Icarus is at the treasure.
-func (m *Maze) LookAround() (mazelib.Survey, error) {
-	if m.end.X == m.icarus.X && m.end.Y == m.icarus.Y {
-		fmt.Printf("Victory achieved in %d steps \n", m.StepsTaken)
-		return mazelib.Survey{}, mazelib.ErrVictory
+// SessionStats reports how many times a single session has solved its maze
+// and its average step count, so a tournament runner can poll per-client
+// progress instead of only the process-wide average.
+func SessionStats(c *gin.Context) {
+	solved, avgSteps, avgEnergy, ok := sessions.Stats(c.Param("session"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "unknown or expired session"})
+		return
 	}
 
-	return m.Discover(m.icarus.X, m.icarus.Y)
+	c.JSON(http.StatusOK, gin.H{"solved": solved, "avgSteps": avgSteps, "avgEnergy": avgEnergy})
 }
 
-// Given two points, survey the room.
-// Will return error if two points are outside of the maze
-func (m *Maze) Discover(x, y int) (mazelib.Survey, error) {
-	if r, err := m.GetRoom(x, y); err != nil {
-		return mazelib.Survey{}, nil
-	} else {
-		return r.Walls, nil
+// sessionIDFromRequest reads the session ID off the X-Session-Id header,
+// falling back to the session cookie set by /awake.
+func sessionIDFromRequest(c *gin.Context) string {
+	if id := c.GetHeader(sessionHeader); id != "" {
+		return id
 	}
+	id, _ := c.Cookie(sessionCookie)
+	return id
 }
 
-// Moves Icarus's position left one step
-// Will not permit moving through walls or out of the maze
-func (m *Maze) MoveLeft() error {
-	s, e := m.LookAround()
-	if e != nil {
-		return e
-	}
-	if s.Left {
-		return errors.New("Can't walk through walls")
-	}
-
-	x, y := m.Icarus()
-	if _, err := m.GetRoom(x-1, y); err != nil {
-		return err
-	}
-
-	m.icarus = mazelib.Coordinate{x - 1, y}
-	m.StepsTaken++
-	return nil
-}
-
-// Moves Icarus's position right one step
-// Will not permit moving through walls or out of the maze
-func (m *Maze) MoveRight() error {
-	s, e := m.LookAround()
-	if e != nil {
-		return e
-	}
-	if s.Right {
-		return errors.New("Can't walk through walls")
+// buildMaze loads a maze from --maze-file if one was given, otherwise picks
+// a generator out of the registry (a named generator, a weighted list of
+// them, or "random") and hands it a single seeded RNG so that, given the
+// same --seed, a run can be replayed exactly.
+func buildMaze() *Maze {
+	if path := viper.GetString("maze-file"); path != "" {
+		m, err := loadMazeFile(path)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(-1)
+		}
+		return m
 	}
 
-	x, y := m.Icarus()
-	if _, err := m.GetRoom(x+1, y); err != nil {
-		return err
+	if viper.GetString("maze") == "file" {
+		m, err := loadMazeFromTextFile(viper.GetString("mazefile"))
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(-1)
+		}
+		return m
 	}
 
-	m.icarus = mazelib.Coordinate{x + 1, y}
-	m.StepsTaken++
-	return nil
-}
-
-// Moves Icarus's position up one step
-// Will not permit moving through walls or out of the maze
-func (m *Maze) MoveUp() error {
-	s, e := m.LookAround()
-	if e != nil {
-		return e
-	}
-	if s.Top {
-		return errors.New("Can't walk through walls")
+	if mazeRand == nil {
+		mazeRand = newMazeRand()
 	}
 
-	x, y := m.Icarus()
-	if _, err := m.GetRoom(x, y-1); err != nil {
-		return err
+	g := pickGenerator(mazeRand)
+	cfg := generators.GenConfig{
+		Width:  viper.GetInt("width"),
+		Height: viper.GetInt("height"),
 	}
 
-	m.icarus = mazelib.Coordinate{x, y - 1}
-	m.StepsTaken++
-	return nil
+	m := g.Generate(cfg, mazeRand)
+	placePOIs(m, mazeRand)
+	return m
 }
 
-// Moves Icarus's position down one step
-// Will not permit moving through walls or out of the maze
-func (m *Maze) MoveDown() error {
-	s, e := m.LookAround()
-	if e != nil {
-		return e
-	}
-	if s.Bottom {
-		return errors.New("Can't walk through walls")
-	}
-
-	x, y := m.Icarus()
-	if _, err := m.GetRoom(x, y+1); err != nil {
-		return err
-	}
+// placePOIs scatters --pois random waypoints across m, avoiding the start,
+// the treasure and any room already picked. It's a no-op when --pois is 0
+// (the default), same as --solve being a no-op when unset.
+func placePOIs(m *Maze, rng *rand.Rand) {
+	n := viper.GetInt("pois")
+	w, h := m.Width(), m.Height()
 
-	m.icarus = mazelib.Coordinate{x, y + 1}
-	m.StepsTaken++
-	return nil
-}
-
-// Creates a maze without any walls
-// Good starting point for additive algorithms
-func emptyMaze() *Maze {
-	z := Maze{}
-	ySize := viper.GetInt("height")
-	xSize := viper.GetInt("width")
-
-	z.rooms = make([][]mazelib.Room, ySize)
-	for y := 0; y < ySize; y++ {
-		z.rooms[y] = make([]mazelib.Room, xSize)
-		for x := 0; x < xSize; x++ {
-			z.rooms[y][x] = mazelib.Room{}
+	for i := 0; i < n; i++ {
+		placed := false
+		for tries := 0; tries < 100 && !placed; tries++ {
+			x, y := rng.Intn(w), rng.Intn(h)
+			if room, err := m.GetRoom(x, y); err != nil || room.POI {
+				continue
+			}
+			if m.AddPOI(x, y) == nil {
+				placed = true
+			}
 		}
 	}
-
-	return &z
 }
 
-// Creates a maze with all walls
-// Good starting point for subtractive algorithms
-func fullMaze() *Maze {
-	z := emptyMaze()
-	ySize := viper.GetInt("height")
-	xSize := viper.GetInt("width")
-
-	for y := 0; y < ySize; y++ {
-		for x := 0; x < xSize; x++ {
-			z.rooms[y][x].Walls = mazelib.Survey{true, true, true, true}
-		}
+// reportSolve runs the solver named by --solve (if any) against m and prints
+// its solution length, so a generator can be sanity-checked for solvability
+// and difficulty-compared against others without playing it by hand.
+func reportSolve(m *Maze) {
+	name := viper.GetString("solve")
+	if name == "" {
+		return
 	}
 
-	return z
-}
-
-
-func backtrackerMaze() *Maze {
-	z := fullMaze()
-	ySize := viper.GetInt("height")
-	xSize := viper.GetInt("width")
-	stackSize := ySize*xSize
-	stackIndex := 0
-	stack := make([]mazelib.Coordinate, xSize*ySize)
-	x := rand.Intn(xSize)
-	y := rand.Intn(ySize)
-	lastC := [4]bool{false, false, false, false}
-	lastCell := 5
-
-	stack[stackIndex] = mazelib.Coordinate{x, y}
-
-	c := 0
-	for c < stackSize{
-
-		free := 4
-		for n:=0; n<4; n++ {
-			 t := (1+lastCell+n)%4
-
-			switch (t) {
-			case 0:
-				if (y-1)<0 {
-					lastC[0] = true
-					free--
-				} else {
-					lastC[0] = z.rooms[y-1][x].Visited
-					if lastC[0] {
-						free--
-					}
-				}
-				break
-			case 1:
-				if (x+1)>=xSize {
-					lastC[1] = true
-					free--
-				} else {
-					lastC[1] = z.rooms[y][x+1].Visited
-					if lastC[1] {
-						free--
-					}
-				}
-				break
-			case 2:
-				if (y+1)>=ySize {
-					lastC[2] = true
-					free--
-				} else {
-					lastC[2] = z.rooms[y+1][x].Visited
-					if lastC[2] {
-						free--
-					}
-				}
-				break
-			case 3:
-				if (x-1)<0 {
-					lastC[3] = true
-					free--
-				} else {
-					lastC[3] = z.rooms[y][x-1].Visited
-					if lastC[3] {
-						free--
-					}
-				}
-				break
-			}
-		}
-
-		if free==0 {
-			lastCell = (lastCell+2)%4
-			lastC[lastCell] = true
-			stackIndex--
-			x = stack[stackIndex].X
-			y = stack[stackIndex].Y
-		} else {
-			t := rand.Intn(free)
-			tm := 0
-			for n:=0; n<4; n++ {
-				if (t+tm)==n && !lastC[n] {
-					t = n
-					break
-				}
-				if lastC[n] {
-					tm++
-				}
-			}
-
-			switch (t) {
-			case 0:
-				z.rooms[y][x].Walls.Top = false
-				y--
-				z.rooms[y][x].Walls.Bottom = false
-				break
-			case 1:
-				z.rooms[y][x].Walls.Right = false
-				x++
-				z.rooms[y][x].Walls.Left = false
-				break
-			case 2:
-				z.rooms[y][x].Walls.Bottom = false
-				y++
-				z.rooms[y][x].Walls.Top = false
-				break
-			case 3:
-				z.rooms[y][x].Walls.Left = false
-				x--
-				z.rooms[y][x].Walls.Right = false
-				break
-			}
-			lastC = [4]bool{false, false, false, false}
-			lastCell = (t+2)%4
-			lastC[lastCell] = true
-			stackIndex++
-			stack[stackIndex] = mazelib.Coordinate{x, y}
-			z.rooms[y][x].Visited = true
-
-			c++
-		}
-
-
-
-
-
+	s, ok := solver.Get(name)
+	if !ok {
+		fmt.Printf("solve: unknown solver %q\n", name)
+		return
 	}
 
-
-	// Random* icarus & treasure
-	icarusX := rand.Intn(xSize)
-	icarusY := rand.Intn(ySize)
-	treasureX := rand.Intn(xSize)
-	treasureY := rand.Intn(ySize)
-
-	// *Don't let them be in the same cell, no fun then
-	for ;; {
-		if icarusX!=treasureX || icarusY!=treasureY {
-			break
-		} else {
-			treasureX = rand.Intn(xSize)
-			treasureY = rand.Intn(ySize)
-		}
+	path, expanded := s.Solve(m)
+	if path == nil {
+		fmt.Printf("solve: %s found no path to the treasure\n", name)
+		return
 	}
-	z.SetStartPoint(icarusX, icarusY)
-	z.SetTreasure(treasureX, treasureY)
 
-	return z
+	fmt.Printf("solve: %s found a %d-room path (%d rooms expanded)\n", name, len(path), expanded)
+	fmt.Println(MazeString(m, path...))
 }
 
-func spikyHorizontalMaze() *Maze {
-	z := fullMaze()
-	ySize := viper.GetInt("height")
-	xSize := viper.GetInt("width")
-
-	middleX := xSize/2
-	middleY := ySize/2
-
-	for x:=0;x<xSize;x++ {
-		for y:=0;y<ySize;y++ {
-			if x>0 && x!=(middleX+1) {
-				z.rooms[y][x].Walls.Left = false
-			}
-			if x<(xSize-1) && x!=middleX {
-				z.rooms[y][x].Walls.Right = false
-			}
-			if x==0 && y>0 {
-				z.rooms[y][x].Walls.Top = false
-			}
-			if x==0 && y<(ySize-1) {
-				z.rooms[y][x].Walls.Bottom = false
-			}
-			if x==(xSize-1) && y>0 {
-				z.rooms[y][x].Walls.Top = false
-			}
-			if x==(xSize-1) && y<(ySize-1) {
-				z.rooms[y][x].Walls.Bottom = false
-			}
-		}
+// loadMazeFile reads a text-format maze fixture off disk.
+func loadMazeFile(path string) (*Maze, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("maze-file: %s", err)
 	}
+	defer f.Close()
 
-	z.rooms[0][middleX].Walls.Right = false
-	z.rooms[ySize-1][middleX].Walls.Right = false
-	z.rooms[0][middleX+1].Walls.Left = false
-	z.rooms[ySize-1][middleX+1].Walls.Left = false
-
-	z.rooms[middleY][xSize-1].Walls.Bottom = true
-	z.rooms[middleY+1][xSize-1].Walls.Top = true
-
-
-	// Random* icarus & treasure
-	icarusX := rand.Intn(xSize)
-	icarusY := rand.Intn(ySize)
-	treasureX := rand.Intn(xSize)
-	treasureY := rand.Intn(ySize)
-
-	// *Don't let them be in the same cell, no fun then
-	for ;; {
-		if icarusX!=treasureX || icarusY!=treasureY {
-			break
-		} else {
-			treasureX = rand.Intn(xSize)
-			treasureY = rand.Intn(ySize)
-		}
+	m, err := mazelib.LoadMazeText(f)
+	if err != nil {
+		return nil, fmt.Errorf("maze-file: %s", err)
 	}
-
-	z.SetStartPoint(icarusX, icarusY)
-	z.SetTreasure(treasureX, treasureY)
-
-	return z
+	return m, nil
 }
 
-func spikyVerticalMaze() *Maze {
-	z := fullMaze()
-	ySize := viper.GetInt("height")
-	xSize := viper.GetInt("width")
-
-	middleY := ySize/2
-
-	for x:=0;x<xSize;x++ {
-		for y:=0;y<ySize;y++ {
-			if y>0 && y!=(middleY) {
-				z.rooms[y][x].Walls.Top = false
-			}
-			if y<(ySize-1) && y!=(middleY-1) {
-				z.rooms[y][x].Walls.Bottom = false
-			}
-			if y==0 && x>0 {
-				z.rooms[y][x].Walls.Left = false
-			}
-			if y==0 && x<(xSize-1) {
-				z.rooms[y][x].Walls.Right = false
-			}
-			if y==(ySize-1) && x>0 {
-				z.rooms[y][x].Walls.Left = false
-			}
-			if y==(ySize-1) && x<(xSize-1) {
-				z.rooms[y][x].Walls.Right = false
-			}
-		}
+// loadMazeFromTextFile reads a hand-drawn ASCII maze off disk for
+// --maze=file, using the classic '#'/' ' wall/path characters.
+func loadMazeFromTextFile(path string) (*Maze, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("mazefile: %s", err)
 	}
+	defer f.Close()
 
-	z.rooms[middleY-1][0].Walls.Bottom = false;
-	z.rooms[middleY][0].Walls.Top = false;
-
-	// Random* icarus & treasure
-	icarusX := rand.Intn(xSize)
-	icarusY := rand.Intn(ySize)
-	treasureX := rand.Intn(xSize)
-	treasureY := rand.Intn(ySize)
-
-	// *Don't let them be in the same cell, no fun then
-	for ;; {
-		if icarusX!=treasureX || icarusY!=treasureY {
-			break
-		} else {
-			treasureX = rand.Intn(xSize)
-			treasureY = rand.Intn(ySize)
-		}
+	m, err := mazelib.LoadMazeFromText(f, ' ', '#')
+	if err != nil {
+		return nil, fmt.Errorf("mazefile: %s", err)
 	}
-
-	z.SetStartPoint(icarusX, icarusY)
-	z.SetTreasure(treasureX, treasureY)
-
-	return z
+	return m, nil
 }
 
-func voidMaze() *Maze {
-	z := emptyMaze()
-	ySize := viper.GetInt("height")
-	xSize := viper.GetInt("width")
-
-	for x:=0;x<xSize;x++ {
-		for y:=0;y<ySize;y++ {
-			if x==0 {
-				z.rooms[y][x].Walls.Left = true
-			}
-			if x==(xSize-1) {
-				z.rooms[y][x].Walls.Right = true
-			}
-			if y==0 {
-				z.rooms[y][x].Walls.Top = true
-			}
-			if y==(ySize-1) {
-				z.rooms[y][x].Walls.Bottom = true
-			}
-		}
+// resolveGenerator looks up name in the registry, falling back to the same
+// selection buildMaze() would make when name is empty. Exits the process
+// with an error message if name is set but unknown, same as any other bad
+// CLI flag in this package.
+func resolveGenerator(name string, rng *rand.Rand) generators.Generator {
+	if name == "" {
+		return pickGenerator(rng)
 	}
 
-
-	// Random* icarus & treasure
-	icarusX := rand.Intn(xSize)
-	icarusY := rand.Intn(ySize)
-	treasureX := rand.Intn(xSize)
-	treasureY := rand.Intn(ySize)
-
-	// *Don't let them be in the same cell, no fun then
-	for ;; {
-		if icarusX!=treasureX || icarusY!=treasureY {
-			break
-		} else {
-			treasureX = rand.Intn(xSize)
-			treasureY = rand.Intn(ySize)
-		}
+	g, ok := generators.Get(name)
+	if !ok {
+		fmt.Printf("unknown generator %q\n", name)
+		os.Exit(-1)
 	}
-
-	z.SetStartPoint(icarusX, icarusY)
-	z.SetTreasure(treasureX, treasureY)
-
-	return z
+	return g
 }
 
-func patternMaze() *Maze {
-	z := fullMaze()
-	ySize := viper.GetInt("height")
-	xSize := viper.GetInt("width")
-
-	xPattern := int(math.Floor(float64(xSize/4)))
-	yPattern := int(math.Floor(float64(ySize/4)))
-
-	// Repeat human-made pattern 4x4
-	for x:=0;x<xPattern;x++ {
-		for y:=0;y<yPattern;y++ {
-			z.rooms[4*y][4*x].Walls = mazelib.Survey{true, false, false, true}
-			z.rooms[4*y][4*x+1].Walls = mazelib.Survey{true, true, true, false}
-			z.rooms[4*y][4*x+2].Walls = mazelib.Survey{true, false, false, true}
-			z.rooms[4*y][4*x+3].Walls = mazelib.Survey{true, true, false, false}
-
-			z.rooms[4*y+1][4*x].Walls = mazelib.Survey{false, true, false, true}
-			z.rooms[4*y+1][4*x+1].Walls = mazelib.Survey{true, false, false, true}
-			z.rooms[4*y+1][4*x+2].Walls = mazelib.Survey{false, true, true, false}
-			z.rooms[4*y+1][4*x+3].Walls = mazelib.Survey{false, true, true, true}
-
-			z.rooms[4*y+2][4*x].Walls = mazelib.Survey{false, false, true, true}
-			z.rooms[4*y+2][4*x+1].Walls = mazelib.Survey{false, false, false, false}
-			z.rooms[4*y+2][4*x+2].Walls = mazelib.Survey{true, true, false, false}
-			z.rooms[4*y+2][4*x+3].Walls = mazelib.Survey{true, true, false, true}
-
-			z.rooms[4*y+3][4*x].Walls = mazelib.Survey{true, false, true, true}
-			z.rooms[4*y+3][4*x+1].Walls = mazelib.Survey{false, true, true, false}
-			z.rooms[4*y+3][4*x+2].Walls = mazelib.Survey{false, false, true, true}
-			z.rooms[4*y+3][4*x+3].Walls = mazelib.Survey{false, true, true, false}
-
-			z.rooms[4*y][4*x+3].Visited = true
-			z.rooms[4*y+1][4*x+3].Visited = true
-			z.rooms[4*y+2][4*x+3].Visited = true
-			z.rooms[4*y+3][4*x].Visited = true
-			z.rooms[4*y+3][4*x+1].Visited = true
-			z.rooms[4*y+3][4*x+2].Visited = true
-			z.rooms[4*y+3][4*x+3].Visited = true
-		}
-	}
+// runDump generates a single maze (via --generator, or the same selection
+// buildMaze() would make) and writes it out as a text fixture at --out.
+func runDump() {
+	rng := newMazeRand()
+	g := resolveGenerator(viper.GetString("generator"), rng)
 
-	// Fill the non-pattern with backtrack maze
-	if xSize>(xPattern*4) || ySize>(yPattern*4) {
-		stackSize := ySize*xSize-(16*xPattern*yPattern)
-		stackIndex := 0
-		stack := make([]mazelib.Coordinate, stackSize)
-		x := xSize-1
-		y := ySize-1
-		lastC := [4]bool{false, true, true, false}
-		lastCell := 2
-
-		stack[stackIndex] = mazelib.Coordinate{x, y}
-
-		c := 0
-		for c < stackSize{
-			free := 3
-			for n:=0; n<3; n++ {
-				t := (1+lastCell+n)%4
-
-				switch (t) {
-				case 0:
-					if (y-1)<0 {
-						lastC[0] = true
-						free--
-					} else {
-						lastC[0] = z.rooms[y-1][x].Visited
-						if lastC[0] {
-							free--
-						}
-					}
-					break
-				case 1:
-					if (x+1)>=xSize {
-						lastC[1] = true
-						free--
-					} else {
-						lastC[1] = z.rooms[y][x+1].Visited
-						if lastC[1] {
-							free--
-						}
-					}
-					break
-				case 2:
-					if (y+1)>=ySize {
-						lastC[2] = true
-						free--
-					} else {
-						lastC[2] = z.rooms[y+1][x].Visited
-						if lastC[2] {
-							free--
-						}
-					}
-					break
-				case 3:
-					if (x-1)<0 {
-						lastC[3] = true
-						free--
-					} else {
-						lastC[3] = z.rooms[y][x-1].Visited
-						if lastC[3] {
-							free--
-						}
-					}
-					break
-				}
-			}
-
-			if free==0 {
-				lastCell = (lastCell+2)%4
-				lastC[lastCell] = true
-				stackIndex--
-				x = stack[stackIndex].X
-				y = stack[stackIndex].Y
-			} else {
-				t := rand.Intn(free)
-				tm := 0
-				for n:=0; n<4; n++ {
-					if (t+tm)==n && !lastC[n] {
-						t = n
-						break
-					}
-					if lastC[n] {
-						tm++
-					}
-				}
-
-				switch (t) {
-				case 0:
-					z.rooms[y][x].Walls.Top = false
-					y--
-					z.rooms[y][x].Walls.Bottom = false
-					break
-				case 1:
-					z.rooms[y][x].Walls.Right = false
-					x++
-					z.rooms[y][x].Walls.Left = false
-					break
-				case 2:
-					z.rooms[y][x].Walls.Bottom = false
-					y++
-					z.rooms[y][x].Walls.Top = false
-					break
-				case 3:
-					z.rooms[y][x].Walls.Left = false
-					x--
-					z.rooms[y][x].Walls.Right = false
-					break
-				}
-				lastC = [4]bool{false, false, false, false}
-				lastCell = (t+2)%4
-				lastC[lastCell] = true
-				stackIndex++
-				stack[stackIndex] = mazelib.Coordinate{x, y}
-				z.rooms[y][x].Visited = true
-
-				c++
-			}
-		}
+	cfg := generators.GenConfig{
+		Width:  viper.GetInt("width"),
+		Height: viper.GetInt("height"),
 	}
+	m := g.Generate(cfg, rng)
 
-	r := 0
-	for x:=0;x<xPattern;x++ {
-		for y := 0; y<yPattern; y++ {
-			if (4*x+3)<xSize {
-				r = rand.Intn(4);
-				z.rooms[4*y+r][4*x+3].Walls.Right = false
-				z.rooms[4*y+r][4*x+4].Walls.Left = false
-			}
-
-			if (4*y+3)<ySize {
-				r = rand.Intn(4);
-				z.rooms[4*y+3][4*x+r].Walls.Bottom = false
-				z.rooms[4*y+4][4*x+r].Walls.Top = false
-			}
-		}
+	out := viper.GetString("out")
+	f, err := os.Create(out)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(-1)
 	}
+	defer f.Close()
 
-
-	// Random* icarus & treasure
-	icarusX := rand.Intn(xSize)
-	icarusY := rand.Intn(ySize)
-	treasureX := rand.Intn(xSize)
-	treasureY := rand.Intn(ySize)
-
-	// *Don't let them be in the same cell, no fun then
-	for ;; {
-		if icarusX!=treasureX || icarusY!=treasureY {
-			break
-		} else {
-			treasureX = rand.Intn(xSize)
-			treasureY = rand.Intn(ySize)
-		}
+	if err := mazelib.SaveMazeText(f, m); err != nil {
+		fmt.Println(err)
+		os.Exit(-1)
 	}
 
-	z.SetStartPoint(icarusX, icarusY)
-	z.SetTreasure(treasureX, treasureY)
-
-	return z
+	fmt.Printf("wrote %dx%d maze to %s\n", m.Width(), m.Height(), out)
 }
 
-type PrimWall struct {
-	X int
-	Y int
-	W int
-}
-
-func shuffle(arr []PrimWall) []PrimWall{
-	t := time.Now()
-	rand.Seed(int64(t.Nanosecond()))
-
-	for i := len(arr) - 1; i > 0; i-- {
-		j := rand.Intn(i)
-		arr[i], arr[j] = arr[j], arr[i]
+// newMazeRand seeds the generator RNG from --seed, falling back to the
+// current time when no seed (or 0) was given.
+func newMazeRand() *rand.Rand {
+	seed := viper.GetInt64("seed")
+	if seed == 0 {
+		seed = time.Now().UTC().UnixNano()
 	}
-	return arr
+	return rand.New(rand.NewSource(seed))
 }
 
-func rightDownMaze() *Maze {
-	z := fullMaze()
-	ySize := viper.GetInt("height")
-	xSize := viper.GetInt("width")
-	stackSize := (xSize-1)*ySize + (ySize-1)*xSize
-
-	wallStack := make([]PrimWall, 0, stackSize)
+// generatorWeight is one entry of the `generators:` config list, e.g.
+//   generators:
+//     - name: prim
+//       weight: 2
+//     - name: backtrack
+//       weight: 1
+type generatorWeight struct {
+	Name   string
+	Weight int
+}
 
-	// VERTICAL WALLS
-	for i:=0;i<(xSize-1);i++ {
-		for j:=0;j<ySize;j++ {
-			n := len(wallStack)
-			wallStack = wallStack[0 : n+1]
-			wallStack[n] = PrimWall{i, j , 1}
+// pickGenerator resolves the `maze`/`generators` config into a concrete
+// Generator: an explicit weighted list takes priority, then a named
+// generator, then "random" (or anything unset) picks uniformly among every
+// registered generator.
+func pickGenerator(rng *rand.Rand) generators.Generator {
+	var weighted []generatorWeight
+	if err := viper.UnmarshalKey("generators", &weighted); err == nil && len(weighted) > 0 {
+		if g := pickWeighted(weighted, rng); g != nil {
+			return g
 		}
 	}
 
-	// HORIZONTAL WALLS
-	for i:=0;i<xSize;i++ {
-		for j:=0;j<(ySize-1);j++ {
-			n := len(wallStack)
-			wallStack = wallStack[0 : n+1]
-			wallStack[n] = PrimWall{i, j , 2}
+	mazeString := viper.GetString("maze")
+	if mazeString != "" && mazeString != "random" {
+		if g, ok := generators.Get(mazeString); ok {
+			return g
 		}
 	}
 
-	shuffle(wallStack)
-
-	wall := wallStack[0]
-	z.rooms[wall.Y][wall.X].Visited = true
-	for ;len(wallStack)>0; {
-
-		wall = wallStack[0]
-		wallStack = wallStack[1:]
-		nx := wall.X
-		ny := wall.Y
-		if (wall.W%2)==1 {
-			nx++
-		} else {
-			ny++
-		}
-
-		if !z.rooms[ny][nx].Visited {
-			if (wall.W%2)==1 {
-				z.rooms[wall.Y][wall.X].Walls.Right = false
-				z.rooms[ny][nx].Walls.Left = false
-			} else {
-				z.rooms[wall.Y][wall.X].Walls.Bottom = false
-				z.rooms[ny][nx].Walls.Top = false
-			}
-			z.rooms[ny][nx].Visited = true
-		}
-
+	uniform := make([]generatorWeight, 0, len(generators.Names()))
+	for _, name := range generators.Names() {
+		uniform = append(uniform, generatorWeight{Name: name, Weight: 1})
 	}
-
-	// Random* icarus & treasure
-	icarusX := rand.Intn(xSize)
-	icarusY := rand.Intn(ySize)
-	treasureX := rand.Intn(xSize)
-	treasureY := rand.Intn(ySize)
-
-	// *Don't let them be in the same cell, no fun then
-	for ;; {
-		if icarusX!=treasureX || icarusY!=treasureY {
-			break
-		} else {
-			treasureX = rand.Intn(xSize)
-			treasureY = rand.Intn(ySize)
-		}
+	if g := pickWeighted(uniform, rng); g != nil {
+		return g
 	}
-	z.SetStartPoint(icarusX, icarusY)
-	z.SetTreasure(treasureX, treasureY)
 
-	return z
+	// Historical default, kept as an ultimate fallback.
+	g, _ := generators.Get("prim")
+	return g
 }
 
-func addPrimWall(wallStack []PrimWall, element PrimWall) []PrimWall {
-	n := len(wallStack)
-	wallStack = wallStack[0 : n+1]
-	wallStack[n] = element
-	return wallStack
-}
-
-func primMaze() *Maze {
-	z := fullMaze()
-	ySize := viper.GetInt("height")
-	xSize := viper.GetInt("width")
-	stackSize := (xSize-1)*ySize + (ySize-1)*xSize
-
-	wallStack := make([]PrimWall, 0, stackSize)
-
-	x := rand.Intn(xSize)
-	y := rand.Intn(ySize)
-
-	if x-1>=0 {
-		wallStack = addPrimWall(wallStack, PrimWall{x, y , 3})
-	}
-	if x+1<xSize {
-		wallStack = addPrimWall(wallStack, PrimWall{x, y , 1})
+// pickWeighted does a weighted random draw over the given list, returning
+// nil if none of the names are registered.
+func pickWeighted(weighted []generatorWeight, rng *rand.Rand) generators.Generator {
+	total := 0
+	for _, w := range weighted {
+		total += w.Weight
 	}
-	if y-1>=0 {
-		wallStack = addPrimWall(wallStack, PrimWall{x, y , 0})
+	if total <= 0 {
+		return nil
 	}
-	if y+1<ySize {
-		wallStack = addPrimWall(wallStack, PrimWall{x, y , 2})
-	}
-
-	z.rooms[y][x].Visited = true
 
-	shuffle(wallStack)
-
-	var wall PrimWall
-	for ;len(wallStack)>0; {
-
-		wall = wallStack[0]
-		wallStack = wallStack[1:]
-		nx := wall.X
-		ny := wall.Y
-		if wall.W==0 {
-			ny--
-		} else if wall.W==1 {
-			nx++
-		} else if wall.W==2 {
-			ny++
-		} else {
-			nx--
-		}
-
-		if !z.rooms[ny][nx].Visited {
-			if wall.W==0 {
-				z.rooms[wall.Y][wall.X].Walls.Top = false
-				z.rooms[ny][nx].Walls.Bottom = false
-			} else if wall.W==1 {
-				z.rooms[wall.Y][wall.X].Walls.Right = false
-				z.rooms[ny][nx].Walls.Left = false
-			} else if wall.W==2 {
-				z.rooms[wall.Y][wall.X].Walls.Bottom = false
-				z.rooms[ny][nx].Walls.Top = false
-			} else {
-				z.rooms[wall.Y][wall.X].Walls.Left = false
-				z.rooms[ny][nx].Walls.Right = false
-			}
-			z.rooms[ny][nx].Visited = true
-
-			if (nx-1)>=0 && !z.rooms[ny][nx-1].Visited {
-				wallStack = addPrimWall(wallStack, PrimWall{nx, ny , 3})
-			}
-			if (nx+1)<xSize && !z.rooms[ny][nx+1].Visited {
-				wallStack = addPrimWall(wallStack, PrimWall{nx, ny , 1})
-			}
-			if (ny-1)>=0 && !z.rooms[ny-1][nx].Visited {
-				wallStack = addPrimWall(wallStack, PrimWall{nx, ny , 0})
+	n := rng.Intn(total)
+	for _, w := range weighted {
+		n -= w.Weight
+		if n < 0 {
+			if g, ok := generators.Get(w.Name); ok {
+				return g
 			}
-			if (ny+1)<ySize && !z.rooms[ny+1][nx].Visited {
-				wallStack = addPrimWall(wallStack, PrimWall{nx, ny , 2})
-			}
-			shuffle(wallStack)
-
-		}
-
-	}
-
-	// Random* icarus & treasure
-	icarusX := rand.Intn(xSize)
-	icarusY := rand.Intn(ySize)
-	treasureX := rand.Intn(xSize)
-	treasureY := rand.Intn(ySize)
-
-	// *Don't let them be in the same cell, no fun then
-	for ;; {
-		if icarusX!=treasureX || icarusY!=treasureY {
-			break
-		} else {
-			treasureX = rand.Intn(xSize)
-			treasureY = rand.Intn(ySize)
+			return nil
 		}
 	}
-	z.SetStartPoint(icarusX, icarusY)
-	z.SetTreasure(treasureX, treasureY)
-
-	return z
+	return nil
 }
 
-
-func circleMaze() *Maze {
-	z := emptyMaze()
-	ySize := viper.GetInt("height")
-	xSize := viper.GetInt("width")
-
-	cx := int(math.Floor(float64(xSize/2)))
-	cy := int(math.Floor(float64(ySize/2)))
-
-	for i:=0;i<cx;i++ {
-		for j:=i;j<(ySize-i);j++ {
-			z.rooms[j][i].Walls.Left = true
-			if (i-1)>=0 {
-				z.rooms[j][i-1].Walls.Right = true
-			}
-			z.rooms[j][xSize-1-i].Walls.Right = true
-			if (xSize-i)<xSize {
-				z.rooms[j][xSize-i].Walls.Left = true
-			}
-		}
-	}
-
-	for j:=0;j<cy;j++ {
-		for i:=j;i<(xSize-j);i++ {
-			z.rooms[j][i].Walls.Top = true
-			if (j-1)>=0 {
-				z.rooms[j-1][i].Walls.Bottom = true
-			}
-			z.rooms[ySize-1-j][i].Walls.Bottom = true
-			if (ySize-j)<ySize {
-				z.rooms[ySize-j][i].Walls.Top = true
-			}
-		}
-	}
-
-	for j:=1;j<cy;j++ {
-		i := j+rand.Intn(xSize-2*j)
-		z.rooms[j][i].Walls.Top = false
-		if (j-1)>=0 {
-			z.rooms[j-1][i].Walls.Bottom = false
-		}
-	}
-
-	// Random* icarus & treasure
-	icarusX := rand.Intn(xSize)
-	icarusY := rand.Intn(ySize)
-	treasureX := rand.Intn(xSize)
-	treasureY := rand.Intn(ySize)
-
-
-	/*z.rooms[0][6].Walls.Bottom = false
-	z.rooms[1][6].Walls.Top = false
-
-	z.rooms[1][10].Walls.Bottom = false
-	z.rooms[2][10].Walls.Top = false
-
-	z.rooms[2][4].Walls.Bottom = false
-	z.rooms[3][4].Walls.Top = false
-
-	z.rooms[3][10].Walls.Bottom = false
-	z.rooms[4][10].Walls.Top = false
-
-
-	icarusX := 11
-	icarusY := 8
-	treasureX := 10
-	treasureY := 2    */
-
-	// *Don't let them be in the same cell, no fun then
-	for ;; {
-		if icarusX!=treasureX || icarusY!=treasureY {
-			break
-		} else {
-			treasureX = rand.Intn(xSize)
-			treasureY = rand.Intn(ySize)
-		}
-	}
-	z.SetStartPoint(icarusX, icarusY)
-	z.SetTreasure(treasureX, treasureY)
-
-	return z
+// Print to the terminal the average steps to solution across every session
+func printResults() {
+	s, e := scores.snapshot()
+	fmt.Printf("Labyrinth solved %d times with an avg of %d steps and %d energy\n", len(s), mazelib.AvgScores(s), mazelib.AvgScores(e))
 }
 
+// MazeString renders m as a block of box-drawing characters. path is
+// optional (solver.Solver.Solve's return value, or any other route through
+// m) - when given, every room on it that isn't otherwise marked is drawn
+// with a "•" so a solution can be eyeballed against the maze it solves.
+func MazeString(m mazelib.MazeI, path ...mazelib.Coordinate) string {
+	onPath := make(map[mazelib.Coordinate]bool, len(path))
+	for _, c := range path {
+		onPath[c] = true
+	}
 
-
-
-func MazeString(m mazelib.MazeI) string {
 	out := ""
 	str := make([][]string, m.Height()*3)
 	for i := 0; i < m.Height(); i++ {
@@ -1198,10 +547,16 @@ func MazeString(m mazelib.MazeI) string {
 				str[i*3+1][j*3+1] = "·"
 			}
 
+			if onPath[mazelib.Coordinate{X: j, Y: i}] {
+				str[i*3+1][j*3+1] = "•"
+			}
+
 			if room.Treasure {
 				str[i*3+1][j*3+1] = "⚿"
 			} else if room.Start {
 				str[i*3+1][j*3+1] = "⚑"
+			} else if room.POI {
+				str[i*3+1][j*3+1] = "◆"
 			}
 
 			x, y := m.Icarus()
@@ -1222,28 +577,68 @@ func MazeString(m mazelib.MazeI) string {
 	return out
 }
 
-func createMaze() *Maze {
+// Wall and room-fill colors for MazePNG.
+var (
+	pngWallColor     = color.Black
+	pngBgColor       = color.White
+	pngVisitedColor  = color.RGBA{225, 225, 225, 255}
+	pngStartColor    = color.RGBA{40, 160, 70, 255}
+	pngTreasureColor = color.RGBA{212, 175, 55, 255}
+	pngIcarusColor   = color.RGBA{220, 40, 40, 255}
+)
+
+// MazePNG renders m as a PNG image: a cellPx x cellPx square per room, with
+// a line drawn along any wall that's up and the start, treasure and Icarus
+// cells filled in distinct colors. Visited rooms get a light gray fill so a
+// solve-in-progress can be told apart from unexplored rooms.
+func MazePNG(m mazelib.MazeI, cellPx int, w io.Writer) error {
+	width, height := m.Width(), m.Height()
+	const wallPx = 2
 
-	// Get the maze flag to change among some types of mazes
-	mazeString := viper.GetString("maze")
-	if mazeString=="void" { // "empty" maze, only outer walls
-		return voidMaze()
-	} else if mazeString=="horizontalspiky" { // this works quite well
-		return spikyHorizontalMaze()
-	} else if mazeString=="verticalspiky" {
-		return spikyVerticalMaze()
-	} else if mazeString=="pattern" { // repeat a human-made pattern over and over
-		return patternMaze()
-	} else if mazeString=="backtrack" { // created using bactrack algo
-		return backtrackerMaze()
-	} else if mazeString=="prim" { // created using prim algo
-		return primMaze()
-	} else if mazeString=="rightdown" {
-		return rightDownMaze()
-	} else if mazeString=="circle" { // concentric circles
-		return circleMaze()
-	} else {
-		return primMaze()
+	img := image.NewRGBA(image.Rect(0, 0, width*cellPx+wallPx, height*cellPx+wallPx))
+	draw.Draw(img, img.Bounds(), image.NewUniform(pngBgColor), image.Point{}, draw.Src)
+
+	ix, iy := m.Icarus()
+
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			room, err := m.GetRoom(x, y)
+			if err != nil {
+				return err
+			}
+
+			x0, y0 := x*cellPx, y*cellPx
+			x1, y1 := x0+cellPx, y0+cellPx
+
+			var fill *image.Uniform
+			switch {
+			case x == ix && y == iy:
+				fill = image.NewUniform(pngIcarusColor)
+			case room.Treasure:
+				fill = image.NewUniform(pngTreasureColor)
+			case room.Start:
+				fill = image.NewUniform(pngStartColor)
+			case room.Visited:
+				fill = image.NewUniform(pngVisitedColor)
+			}
+			if fill != nil {
+				draw.Draw(img, image.Rect(x0+wallPx, y0+wallPx, x1, y1), fill, image.Point{}, draw.Src)
+			}
+
+			if room.Walls.Top {
+				draw.Draw(img, image.Rect(x0, y0, x1+wallPx, y0+wallPx), image.NewUniform(pngWallColor), image.Point{}, draw.Src)
+			}
+			if room.Walls.Bottom {
+				draw.Draw(img, image.Rect(x0, y1, x1+wallPx, y1+wallPx), image.NewUniform(pngWallColor), image.Point{}, draw.Src)
+			}
+			if room.Walls.Left {
+				draw.Draw(img, image.Rect(x0, y0, x0+wallPx, y1+wallPx), image.NewUniform(pngWallColor), image.Point{}, draw.Src)
+			}
+			if room.Walls.Right {
+				draw.Draw(img, image.Rect(x1, y0, x1+wallPx, y1+wallPx), image.NewUniform(pngWallColor), image.Point{}, draw.Src)
+			}
+		}
 	}
 
+	return png.Encode(w, img)
 }
@@ -0,0 +1,174 @@
+// Copyright © 2015 Steve Francia <spf@spf13.com>.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+//
+
+package generators
+
+import (
+	"math/rand"
+
+	"github.com/golangchallenge/gc6/mazelib"
+)
+
+func init() {
+	RegisterGenerator(backtrackerGenerator{})
+}
+
+type backtrackerGenerator struct{}
+
+func (backtrackerGenerator) Name() string { return "backtrack" }
+
+func (g backtrackerGenerator) Generate(cfg GenConfig, rng *rand.Rand) *mazelib.Maze {
+	grid := newFullGrid(cfg.Width, cfg.Height)
+	carveBacktracker(roomGrid(grid), cfg.Width, cfg.Height, rng)
+
+	z := mazelib.NewMazeFromRooms(grid)
+	PlaceIcarusAndTreasure(z, rng)
+	return z
+}
+
+// GeneratePackedBacktracker is backtrackerGenerator.Generate's build option
+// for grids too large for a [][]mazelib.Room to be affordable: it runs the
+// exact same carving algorithm directly into a mazelib.PackedMaze. It isn't
+// wired into the generator registry, since daedalus's JSON protocol isn't
+// meant for mazes of this size - it's for callers driving PackedMaze
+// directly.
+func GeneratePackedBacktracker(width, height int, rng *rand.Rand) *mazelib.PackedMaze {
+	m := mazelib.NewFullPackedMaze(width, height)
+	carveBacktracker(packedGrid{m}, width, height, rng)
+
+	x, y := rng.Intn(width), rng.Intn(height)
+	tx, ty := rng.Intn(width), rng.Intn(height)
+	for tx == x && ty == y {
+		tx, ty = rng.Intn(width), rng.Intn(height)
+	}
+	m.SetStartPoint(x, y)
+	m.SetTreasure(tx, ty)
+
+	return m
+}
+
+// carveBacktracker runs a randomized depth-first backtracker over grid,
+// carving a perfect maze in place. Shared by the backtrack, portal and
+// weighted generators and by GeneratePackedBacktracker; the pattern
+// generator fills its fringe with its own fillPatternFringe instead.
+func carveBacktracker(grid cellGrid, xSize, ySize int, rng *rand.Rand) {
+	// The start cell is visited up front, so carving every other cell takes
+	// exactly xSize*ySize-1 steps.
+	stackSize := ySize*xSize - 1
+	stackIndex := 0
+	stack := make([]mazelib.Coordinate, xSize*ySize)
+	x := rng.Intn(xSize)
+	y := rng.Intn(ySize)
+	lastC := [4]bool{false, false, false, false}
+	lastCell := 5
+
+	stack[stackIndex] = mazelib.Coordinate{X: x, Y: y}
+	grid.SetVisited(x, y)
+
+	c := 0
+	for c < stackSize {
+
+		free := 4
+		for n := 0; n < 4; n++ {
+			t := (1 + lastCell + n) % 4
+
+			switch t {
+			case 0:
+				if (y - 1) < 0 {
+					lastC[0] = true
+					free--
+				} else {
+					lastC[0] = grid.Visited(x, y-1)
+					if lastC[0] {
+						free--
+					}
+				}
+			case 1:
+				if (x + 1) >= xSize {
+					lastC[1] = true
+					free--
+				} else {
+					lastC[1] = grid.Visited(x+1, y)
+					if lastC[1] {
+						free--
+					}
+				}
+			case 2:
+				if (y + 1) >= ySize {
+					lastC[2] = true
+					free--
+				} else {
+					lastC[2] = grid.Visited(x, y+1)
+					if lastC[2] {
+						free--
+					}
+				}
+			case 3:
+				if (x - 1) < 0 {
+					lastC[3] = true
+					free--
+				} else {
+					lastC[3] = grid.Visited(x-1, y)
+					if lastC[3] {
+						free--
+					}
+				}
+			}
+		}
+
+		if free == 0 {
+			lastCell = (lastCell + 2) % 4
+			lastC[lastCell] = true
+			stackIndex--
+			x = stack[stackIndex].X
+			y = stack[stackIndex].Y
+		} else {
+			t := rng.Intn(free)
+			tm := 0
+			for n := 0; n < 4; n++ {
+				if (t+tm) == n && !lastC[n] {
+					t = n
+					break
+				}
+				if lastC[n] {
+					tm++
+				}
+			}
+
+			switch t {
+			case 0:
+				grid.OpenTop(x, y)
+				y--
+			case 1:
+				grid.OpenRight(x, y)
+				x++
+			case 2:
+				grid.OpenBottom(x, y)
+				y++
+			case 3:
+				grid.OpenLeft(x, y)
+				x--
+			}
+			lastC = [4]bool{false, false, false, false}
+			lastCell = (t + 2) % 4
+			lastC[lastCell] = true
+			stackIndex++
+			stack[stackIndex] = mazelib.Coordinate{X: x, Y: y}
+			grid.SetVisited(x, y)
+
+			c++
+		}
+	}
+}
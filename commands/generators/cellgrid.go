@@ -0,0 +1,81 @@
+// Copyright © 2015 Steve Francia <spf@spf13.com>.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+//
+
+package generators
+
+import "github.com/golangchallenge/gc6/mazelib"
+
+// cellGrid is the carving surface a maze algorithm needs: "has this cell
+// been visited" and "open the wall in this direction, and the matching one
+// on the neighbor". It's implemented by roomGrid (the normal [][]Room
+// backing store) and packedGrid (mazelib.PackedMaze), so an algorithm
+// written against cellGrid can target either one without caring which.
+type cellGrid interface {
+	Visited(x, y int) bool
+	SetVisited(x, y int)
+	OpenTop(x, y int)
+	OpenRight(x, y int)
+	OpenBottom(x, y int)
+	OpenLeft(x, y int)
+}
+
+// roomGrid adapts the normal [][]mazelib.Room backing store to cellGrid.
+type roomGrid [][]mazelib.Room
+
+func (g roomGrid) Visited(x, y int) bool { return g[y][x].Visited }
+func (g roomGrid) SetVisited(x, y int)   { g[y][x].Visited = true }
+
+func (g roomGrid) OpenTop(x, y int) {
+	g[y][x].Walls.Top = false
+	if y > 0 {
+		g[y-1][x].Walls.Bottom = false
+	}
+}
+
+func (g roomGrid) OpenBottom(x, y int) {
+	g[y][x].Walls.Bottom = false
+	if y+1 < len(g) {
+		g[y+1][x].Walls.Top = false
+	}
+}
+
+func (g roomGrid) OpenLeft(x, y int) {
+	g[y][x].Walls.Left = false
+	if x > 0 {
+		g[y][x-1].Walls.Right = false
+	}
+}
+
+func (g roomGrid) OpenRight(x, y int) {
+	g[y][x].Walls.Right = false
+	if x+1 < len(g[y]) {
+		g[y][x+1].Walls.Left = false
+	}
+}
+
+// packedGrid adapts a *mazelib.PackedMaze to cellGrid, so the same carving
+// algorithms can build directly into the bit-packed backing store instead
+// of a full [][]Room grid - the "build option" for scaling to much larger
+// mazes without the per-cell Room overhead.
+type packedGrid struct {
+	m *mazelib.PackedMaze
+}
+
+func (g packedGrid) Visited(x, y int) bool { return g.m.Visited(x, y) }
+func (g packedGrid) SetVisited(x, y int)   { g.m.SetVisited(x, y) }
+func (g packedGrid) OpenTop(x, y int)      { g.m.OpenTop(x, y) }
+func (g packedGrid) OpenRight(x, y int)    { g.m.OpenRight(x, y) }
+func (g packedGrid) OpenBottom(x, y int)   { g.m.OpenBottom(x, y) }
+func (g packedGrid) OpenLeft(x, y int)     { g.m.OpenLeft(x, y) }
@@ -0,0 +1,212 @@
+// Copyright © 2015 Steve Francia <spf@spf13.com>.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+//
+
+package generators
+
+import (
+	"math"
+	"math/rand"
+
+	"github.com/golangchallenge/gc6/mazelib"
+)
+
+func init() {
+	RegisterGenerator(patternGenerator{})
+}
+
+// patternGenerator repeats a hand-made 4x4 pattern over the grid, then
+// fills whatever doesn't divide evenly into 4 with a plain backtracker, and
+// finally knocks one hole per pattern tile into its neighbours so the tiles
+// connect to each other and to the backtracked fringe.
+type patternGenerator struct{}
+
+func (patternGenerator) Name() string { return "pattern" }
+
+func (g patternGenerator) Generate(cfg GenConfig, rng *rand.Rand) *mazelib.Maze {
+	xSize, ySize := cfg.Width, cfg.Height
+	grid := newFullGrid(xSize, ySize)
+
+	xPattern := int(math.Floor(float64(xSize / 4)))
+	yPattern := int(math.Floor(float64(ySize / 4)))
+
+	for x := 0; x < xPattern; x++ {
+		for y := 0; y < yPattern; y++ {
+			grid[4*y][4*x].Walls = mazelib.Survey{Top: true, Right: false, Bottom: false, Left: true}
+			grid[4*y][4*x+1].Walls = mazelib.Survey{Top: true, Right: true, Bottom: true, Left: false}
+			grid[4*y][4*x+2].Walls = mazelib.Survey{Top: true, Right: false, Bottom: false, Left: true}
+			grid[4*y][4*x+3].Walls = mazelib.Survey{Top: true, Right: true, Bottom: false, Left: false}
+
+			grid[4*y+1][4*x].Walls = mazelib.Survey{Top: false, Right: true, Bottom: false, Left: true}
+			grid[4*y+1][4*x+1].Walls = mazelib.Survey{Top: true, Right: false, Bottom: false, Left: true}
+			grid[4*y+1][4*x+2].Walls = mazelib.Survey{Top: false, Right: true, Bottom: true, Left: false}
+			grid[4*y+1][4*x+3].Walls = mazelib.Survey{Top: false, Right: true, Bottom: true, Left: true}
+
+			grid[4*y+2][4*x].Walls = mazelib.Survey{Top: false, Right: false, Bottom: true, Left: true}
+			grid[4*y+2][4*x+1].Walls = mazelib.Survey{Top: false, Right: false, Bottom: false, Left: false}
+			grid[4*y+2][4*x+2].Walls = mazelib.Survey{Top: true, Right: true, Bottom: false, Left: false}
+			grid[4*y+2][4*x+3].Walls = mazelib.Survey{Top: true, Right: true, Bottom: false, Left: true}
+
+			grid[4*y+3][4*x].Walls = mazelib.Survey{Top: true, Right: false, Bottom: true, Left: true}
+			grid[4*y+3][4*x+1].Walls = mazelib.Survey{Top: false, Right: true, Bottom: true, Left: false}
+			grid[4*y+3][4*x+2].Walls = mazelib.Survey{Top: false, Right: false, Bottom: true, Left: true}
+			grid[4*y+3][4*x+3].Walls = mazelib.Survey{Top: false, Right: true, Bottom: true, Left: false}
+
+			grid[4*y][4*x+3].Visited = true
+			grid[4*y+1][4*x+3].Visited = true
+			grid[4*y+2][4*x+3].Visited = true
+			grid[4*y+3][4*x].Visited = true
+			grid[4*y+3][4*x+1].Visited = true
+			grid[4*y+3][4*x+2].Visited = true
+			grid[4*y+3][4*x+3].Visited = true
+		}
+	}
+
+	// Fill whatever the 4x4 pattern doesn't cover with a backtracker, using
+	// a smaller variant that only considers 3 of the 4 neighbours since it
+	// starts from a corner that's already bordered by pattern tiles.
+	if xSize > (xPattern*4) || ySize > (yPattern*4) {
+		fillPatternFringe(grid, xSize, ySize, xPattern, yPattern, rng)
+	}
+
+	for x := 0; x < xPattern; x++ {
+		for y := 0; y < yPattern; y++ {
+			if (4*x + 3) < xSize {
+				r := rng.Intn(4)
+				grid[4*y+r][4*x+3].Walls.Right = false
+				grid[4*y+r][4*x+4].Walls.Left = false
+			}
+
+			if (4*y + 3) < ySize {
+				r := rng.Intn(4)
+				grid[4*y+3][4*x+r].Walls.Bottom = false
+				grid[4*y+4][4*x+r].Walls.Top = false
+			}
+		}
+	}
+
+	z := mazelib.NewMazeFromRooms(grid)
+	PlaceIcarusAndTreasure(z, rng)
+	return z
+}
+
+func fillPatternFringe(grid [][]mazelib.Room, xSize, ySize, xPattern, yPattern int, rng *rand.Rand) {
+	stackSize := ySize*xSize - (16 * xPattern * yPattern)
+	stackIndex := 0
+	stack := make([]mazelib.Coordinate, stackSize)
+	x := xSize - 1
+	y := ySize - 1
+	lastC := [4]bool{false, true, true, false}
+	lastCell := 2
+
+	stack[stackIndex] = mazelib.Coordinate{X: x, Y: y}
+
+	c := 0
+	for c < stackSize {
+		free := 3
+		for n := 0; n < 3; n++ {
+			t := (1 + lastCell + n) % 4
+
+			switch t {
+			case 0:
+				if (y - 1) < 0 {
+					lastC[0] = true
+					free--
+				} else {
+					lastC[0] = grid[y-1][x].Visited
+					if lastC[0] {
+						free--
+					}
+				}
+			case 1:
+				if (x + 1) >= xSize {
+					lastC[1] = true
+					free--
+				} else {
+					lastC[1] = grid[y][x+1].Visited
+					if lastC[1] {
+						free--
+					}
+				}
+			case 2:
+				if (y + 1) >= ySize {
+					lastC[2] = true
+					free--
+				} else {
+					lastC[2] = grid[y+1][x].Visited
+					if lastC[2] {
+						free--
+					}
+				}
+			case 3:
+				if (x - 1) < 0 {
+					lastC[3] = true
+					free--
+				} else {
+					lastC[3] = grid[y][x-1].Visited
+					if lastC[3] {
+						free--
+					}
+				}
+			}
+		}
+
+		if free == 0 {
+			lastCell = (lastCell + 2) % 4
+			lastC[lastCell] = true
+			stackIndex--
+			x = stack[stackIndex].X
+			y = stack[stackIndex].Y
+		} else {
+			t := rng.Intn(free)
+			tm := 0
+			for n := 0; n < 4; n++ {
+				if (t+tm) == n && !lastC[n] {
+					t = n
+					break
+				}
+				if lastC[n] {
+					tm++
+				}
+			}
+
+			switch t {
+			case 0:
+				grid[y][x].Walls.Top = false
+				y--
+				grid[y][x].Walls.Bottom = false
+			case 1:
+				grid[y][x].Walls.Right = false
+				x++
+				grid[y][x].Walls.Left = false
+			case 2:
+				grid[y][x].Walls.Bottom = false
+				y++
+				grid[y][x].Walls.Top = false
+			case 3:
+				grid[y][x].Walls.Left = false
+				x--
+				grid[y][x].Walls.Right = false
+			}
+			lastC = [4]bool{false, false, false, false}
+			lastCell = (t + 2) % 4
+			lastC[lastCell] = true
+			stackIndex++
+			stack[stackIndex] = mazelib.Coordinate{X: x, Y: y}
+			grid[y][x].Visited = true
+
+			c++
+		}
+	}
+}
@@ -0,0 +1,75 @@
+// Copyright © 2015 Steve Francia <spf@spf13.com>.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+//
+
+package generators
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+
+	"github.com/golangchallenge/gc6/mazelib"
+	"github.com/spf13/viper"
+)
+
+func init() {
+	RegisterGenerator(portalGenerator{})
+}
+
+// portalGenerator overlays a few portal pairs on top of a plain backtracker
+// maze. Endpoints are kept well apart (in Manhattan distance) so a portal
+// actually shortcuts a corridor instead of connecting two neighbouring
+// cells that were already a step away from each other.
+type portalGenerator struct{}
+
+func (portalGenerator) Name() string { return "portal" }
+
+func (g portalGenerator) Generate(cfg GenConfig, rng *rand.Rand) *mazelib.Maze {
+	xSize, ySize := cfg.Width, cfg.Height
+	grid := newFullGrid(xSize, ySize)
+	carveBacktracker(roomGrid(grid), xSize, ySize, rng)
+
+	z := mazelib.NewMazeFromRooms(grid)
+	PlaceIcarusAndTreasure(z, rng)
+
+	minSeparation := (xSize + ySize) / 2
+
+	pairs := viper.GetInt("portals")
+	if pairs == 0 {
+		pairs = 3
+		if xSize*ySize < 30 {
+			pairs = 1
+		}
+	}
+
+	for p := 0; p < pairs; p++ {
+		var a, b mazelib.Coordinate
+		for tries := 0; tries < 100; tries++ {
+			a = mazelib.Coordinate{X: rng.Intn(xSize), Y: rng.Intn(ySize)}
+			b = mazelib.Coordinate{X: rng.Intn(xSize), Y: rng.Intn(ySize)}
+
+			dist := int(math.Abs(float64(a.X-b.X)) + math.Abs(float64(a.Y-b.Y)))
+			if dist < minSeparation {
+				continue
+			}
+
+			if err := z.AddPortal(fmt.Sprintf("portal-%d", p), a, b); err == nil {
+				break
+			}
+		}
+	}
+
+	return z
+}
@@ -0,0 +1,196 @@
+// Copyright © 2015 Steve Francia <spf@spf13.com>.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+//
+
+package generators
+
+import (
+	"math/rand"
+
+	"github.com/golangchallenge/gc6/mazelib"
+)
+
+func init() {
+	RegisterGenerator(primGenerator{})
+	RegisterGenerator(rightDownGenerator{})
+}
+
+// primWall is a candidate wall to knock down: the room it belongs to (X, Y)
+// and which wall (0 top, 1 right, 2 bottom, 3 left).
+type primWall struct {
+	X int
+	Y int
+	W int
+}
+
+func shuffleWalls(arr []primWall, rng *rand.Rand) []primWall {
+	for i := len(arr) - 1; i > 0; i-- {
+		j := rng.Intn(i)
+		arr[i], arr[j] = arr[j], arr[i]
+	}
+	return arr
+}
+
+func addPrimWall(wallStack []primWall, element primWall) []primWall {
+	n := len(wallStack)
+	wallStack = wallStack[0 : n+1]
+	wallStack[n] = element
+	return wallStack
+}
+
+type primGenerator struct{}
+
+func (primGenerator) Name() string { return "prim" }
+
+func (g primGenerator) Generate(cfg GenConfig, rng *rand.Rand) *mazelib.Maze {
+	xSize, ySize := cfg.Width, cfg.Height
+	grid := newFullGrid(xSize, ySize)
+	stackSize := (xSize-1)*ySize + (ySize-1)*xSize
+
+	wallStack := make([]primWall, 0, stackSize)
+
+	x := rng.Intn(xSize)
+	y := rng.Intn(ySize)
+
+	if x-1 >= 0 {
+		wallStack = addPrimWall(wallStack, primWall{x, y, 3})
+	}
+	if x+1 < xSize {
+		wallStack = addPrimWall(wallStack, primWall{x, y, 1})
+	}
+	if y-1 >= 0 {
+		wallStack = addPrimWall(wallStack, primWall{x, y, 0})
+	}
+	if y+1 < ySize {
+		wallStack = addPrimWall(wallStack, primWall{x, y, 2})
+	}
+
+	grid[y][x].Visited = true
+
+	shuffleWalls(wallStack, rng)
+
+	var wall primWall
+	for len(wallStack) > 0 {
+
+		wall = wallStack[0]
+		wallStack = wallStack[1:]
+		nx := wall.X
+		ny := wall.Y
+		if wall.W == 0 {
+			ny--
+		} else if wall.W == 1 {
+			nx++
+		} else if wall.W == 2 {
+			ny++
+		} else {
+			nx--
+		}
+
+		if !grid[ny][nx].Visited {
+			if wall.W == 0 {
+				grid[wall.Y][wall.X].Walls.Top = false
+				grid[ny][nx].Walls.Bottom = false
+			} else if wall.W == 1 {
+				grid[wall.Y][wall.X].Walls.Right = false
+				grid[ny][nx].Walls.Left = false
+			} else if wall.W == 2 {
+				grid[wall.Y][wall.X].Walls.Bottom = false
+				grid[ny][nx].Walls.Top = false
+			} else {
+				grid[wall.Y][wall.X].Walls.Left = false
+				grid[ny][nx].Walls.Right = false
+			}
+			grid[ny][nx].Visited = true
+
+			if (nx-1) >= 0 && !grid[ny][nx-1].Visited {
+				wallStack = addPrimWall(wallStack, primWall{nx, ny, 3})
+			}
+			if (nx+1) < xSize && !grid[ny][nx+1].Visited {
+				wallStack = addPrimWall(wallStack, primWall{nx, ny, 1})
+			}
+			if (ny-1) >= 0 && !grid[ny-1][nx].Visited {
+				wallStack = addPrimWall(wallStack, primWall{nx, ny, 0})
+			}
+			if (ny+1) < ySize && !grid[ny+1][nx].Visited {
+				wallStack = addPrimWall(wallStack, primWall{nx, ny, 2})
+			}
+			shuffleWalls(wallStack, rng)
+		}
+	}
+
+	z := mazelib.NewMazeFromRooms(grid)
+	PlaceIcarusAndTreasure(z, rng)
+	return z
+}
+
+// rightDownGenerator is also a randomized-Prim maze, but it seeds the wall
+// list from every vertical/horizontal wall up front instead of growing the
+// frontier outward from a single cell.
+type rightDownGenerator struct{}
+
+func (rightDownGenerator) Name() string { return "rightdown" }
+
+func (g rightDownGenerator) Generate(cfg GenConfig, rng *rand.Rand) *mazelib.Maze {
+	xSize, ySize := cfg.Width, cfg.Height
+	grid := newFullGrid(xSize, ySize)
+	stackSize := (xSize-1)*ySize + (ySize-1)*xSize
+
+	wallStack := make([]primWall, 0, stackSize)
+
+	// VERTICAL WALLS
+	for i := 0; i < (xSize - 1); i++ {
+		for j := 0; j < ySize; j++ {
+			wallStack = addPrimWall(wallStack, primWall{i, j, 1})
+		}
+	}
+
+	// HORIZONTAL WALLS
+	for i := 0; i < xSize; i++ {
+		for j := 0; j < (ySize - 1); j++ {
+			wallStack = addPrimWall(wallStack, primWall{i, j, 2})
+		}
+	}
+
+	shuffleWalls(wallStack, rng)
+
+	wall := wallStack[0]
+	grid[wall.Y][wall.X].Visited = true
+	for len(wallStack) > 0 {
+
+		wall = wallStack[0]
+		wallStack = wallStack[1:]
+		nx := wall.X
+		ny := wall.Y
+		if (wall.W % 2) == 1 {
+			nx++
+		} else {
+			ny++
+		}
+
+		if !grid[ny][nx].Visited {
+			if (wall.W % 2) == 1 {
+				grid[wall.Y][wall.X].Walls.Right = false
+				grid[ny][nx].Walls.Left = false
+			} else {
+				grid[wall.Y][wall.X].Walls.Bottom = false
+				grid[ny][nx].Walls.Top = false
+			}
+			grid[ny][nx].Visited = true
+		}
+	}
+
+	z := mazelib.NewMazeFromRooms(grid)
+	PlaceIcarusAndTreasure(z, rng)
+	return z
+}
@@ -0,0 +1,118 @@
+// Copyright © 2015 Steve Francia <spf@spf13.com>.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+//
+
+package generators
+
+import (
+	"math/rand"
+
+	"github.com/golangchallenge/gc6/mazelib"
+)
+
+func init() {
+	RegisterGenerator(binaryTreeGenerator{})
+	RegisterGenerator(sidewinderGenerator{})
+}
+
+// binaryTreeGenerator visits every cell in row-major order and carves
+// either its north or east wall (whichever are available), picked at
+// random. It's the simplest maze algorithm there is - no backtracking, no
+// bookkeeping - at the cost of always leaving a strong diagonal bias and
+// two fully open corridors along the top row and right column.
+type binaryTreeGenerator struct{}
+
+func (binaryTreeGenerator) Name() string { return "binarytree" }
+
+func (g binaryTreeGenerator) Generate(cfg GenConfig, rng *rand.Rand) *mazelib.Maze {
+	xSize, ySize := cfg.Width, cfg.Height
+	grid := newFullGrid(xSize, ySize)
+
+	for y := 0; y < ySize; y++ {
+		for x := 0; x < xSize; x++ {
+			canCarveNorth := y > 0
+			canCarveEast := x < xSize-1
+
+			switch {
+			case canCarveNorth && canCarveEast:
+				if rng.Intn(2) == 0 {
+					carveNorth(grid, x, y)
+				} else {
+					carveEast(grid, x, y)
+				}
+			case canCarveNorth:
+				carveNorth(grid, x, y)
+			case canCarveEast:
+				carveEast(grid, x, y)
+			}
+		}
+	}
+
+	z := mazelib.NewMazeFromRooms(grid)
+	PlaceIcarusAndTreasure(z, rng)
+	return z
+}
+
+// sidewinderGenerator is binary tree's sibling: it walks row by row,
+// accumulating a run of cells until it decides to close the run out by
+// carving north from a random member of it, and only ever carves east
+// otherwise. The result still has a bias (every row is connected upward at
+// least once) but loses binary tree's perfectly straight top/right
+// corridors.
+type sidewinderGenerator struct{}
+
+func (sidewinderGenerator) Name() string { return "sidewinder" }
+
+func (g sidewinderGenerator) Generate(cfg GenConfig, rng *rand.Rand) *mazelib.Maze {
+	xSize, ySize := cfg.Width, cfg.Height
+	grid := newFullGrid(xSize, ySize)
+
+	for y := 0; y < ySize; y++ {
+		run := make([]int, 0, xSize)
+
+		for x := 0; x < xSize; x++ {
+			run = append(run, x)
+
+			atEasternBoundary := x == xSize-1
+			atNorthernBoundary := y == 0
+			closeOut := atEasternBoundary || (!atNorthernBoundary && rng.Intn(2) == 0)
+
+			if closeOut {
+				member := run[rng.Intn(len(run))]
+				if !atNorthernBoundary {
+					carveNorth(grid, member, y)
+				}
+				run = run[:0]
+			} else {
+				carveEast(grid, x, y)
+			}
+		}
+	}
+
+	z := mazelib.NewMazeFromRooms(grid)
+	PlaceIcarusAndTreasure(z, rng)
+	return z
+}
+
+// carveNorth opens the wall between (x,y) and its northern neighbour.
+func carveNorth(grid [][]mazelib.Room, x, y int) {
+	grid[y][x].Walls.Top = false
+	grid[y-1][x].Walls.Bottom = false
+}
+
+// carveEast opens the wall between (x,y) and its eastern neighbour.
+func carveEast(grid [][]mazelib.Room, x, y int) {
+	grid[y][x].Walls.Right = false
+	grid[y][x+1].Walls.Left = false
+}
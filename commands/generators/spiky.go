@@ -0,0 +1,115 @@
+// Copyright © 2015 Steve Francia <spf@spf13.com>.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+//
+
+package generators
+
+import (
+	"math/rand"
+
+	"github.com/golangchallenge/gc6/mazelib"
+)
+
+func init() {
+	RegisterGenerator(spikyHorizontalGenerator{})
+	RegisterGenerator(spikyVerticalGenerator{})
+}
+
+type spikyHorizontalGenerator struct{}
+
+func (spikyHorizontalGenerator) Name() string { return "horizontalspiky" }
+
+func (g spikyHorizontalGenerator) Generate(cfg GenConfig, rng *rand.Rand) *mazelib.Maze {
+	xSize, ySize := cfg.Width, cfg.Height
+	grid := newFullGrid(xSize, ySize)
+
+	middleX := xSize / 2
+	middleY := ySize / 2
+
+	for x := 0; x < xSize; x++ {
+		for y := 0; y < ySize; y++ {
+			if x > 0 && x != (middleX+1) {
+				grid[y][x].Walls.Left = false
+			}
+			if x < (xSize-1) && x != middleX {
+				grid[y][x].Walls.Right = false
+			}
+			if x == 0 && y > 0 {
+				grid[y][x].Walls.Top = false
+			}
+			if x == 0 && y < (ySize-1) {
+				grid[y][x].Walls.Bottom = false
+			}
+			if x == (xSize-1) && y > 0 {
+				grid[y][x].Walls.Top = false
+			}
+			if x == (xSize-1) && y < (ySize-1) {
+				grid[y][x].Walls.Bottom = false
+			}
+		}
+	}
+
+	grid[0][middleX].Walls.Right = false
+	grid[ySize-1][middleX].Walls.Right = false
+	grid[0][middleX+1].Walls.Left = false
+	grid[ySize-1][middleX+1].Walls.Left = false
+
+	grid[middleY][xSize-1].Walls.Bottom = true
+	grid[middleY+1][xSize-1].Walls.Top = true
+
+	z := mazelib.NewMazeFromRooms(grid)
+	PlaceIcarusAndTreasure(z, rng)
+	return z
+}
+
+type spikyVerticalGenerator struct{}
+
+func (spikyVerticalGenerator) Name() string { return "verticalspiky" }
+
+func (g spikyVerticalGenerator) Generate(cfg GenConfig, rng *rand.Rand) *mazelib.Maze {
+	xSize, ySize := cfg.Width, cfg.Height
+	grid := newFullGrid(xSize, ySize)
+
+	middleY := ySize / 2
+
+	for x := 0; x < xSize; x++ {
+		for y := 0; y < ySize; y++ {
+			if y > 0 && y != middleY {
+				grid[y][x].Walls.Top = false
+			}
+			if y < (ySize-1) && y != (middleY-1) {
+				grid[y][x].Walls.Bottom = false
+			}
+			if y == 0 && x > 0 {
+				grid[y][x].Walls.Left = false
+			}
+			if y == 0 && x < (xSize-1) {
+				grid[y][x].Walls.Right = false
+			}
+			if y == (ySize-1) && x > 0 {
+				grid[y][x].Walls.Left = false
+			}
+			if y == (ySize-1) && x < (xSize-1) {
+				grid[y][x].Walls.Right = false
+			}
+		}
+	}
+
+	grid[middleY-1][0].Walls.Bottom = false
+	grid[middleY][0].Walls.Top = false
+
+	z := mazelib.NewMazeFromRooms(grid)
+	PlaceIcarusAndTreasure(z, rng)
+	return z
+}
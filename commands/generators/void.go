@@ -0,0 +1,110 @@
+// Copyright © 2015 Steve Francia <spf@spf13.com>.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+//
+
+package generators
+
+import (
+	"math"
+	"math/rand"
+
+	"github.com/golangchallenge/gc6/mazelib"
+)
+
+func init() {
+	RegisterGenerator(voidGenerator{})
+	RegisterGenerator(circleGenerator{})
+}
+
+// voidGenerator produces an "empty" maze: no interior walls, just the
+// outer boundary.
+type voidGenerator struct{}
+
+func (voidGenerator) Name() string { return "void" }
+
+func (g voidGenerator) Generate(cfg GenConfig, rng *rand.Rand) *mazelib.Maze {
+	xSize, ySize := cfg.Width, cfg.Height
+	grid := newEmptyGrid(xSize, ySize)
+
+	for x := 0; x < xSize; x++ {
+		for y := 0; y < ySize; y++ {
+			if x == 0 {
+				grid[y][x].Walls.Left = true
+			}
+			if x == (xSize - 1) {
+				grid[y][x].Walls.Right = true
+			}
+			if y == 0 {
+				grid[y][x].Walls.Top = true
+			}
+			if y == (ySize - 1) {
+				grid[y][x].Walls.Bottom = true
+			}
+		}
+	}
+
+	z := mazelib.NewMazeFromRooms(grid)
+	PlaceIcarusAndTreasure(z, rng)
+	return z
+}
+
+type circleGenerator struct{}
+
+func (circleGenerator) Name() string { return "circle" }
+
+func (g circleGenerator) Generate(cfg GenConfig, rng *rand.Rand) *mazelib.Maze {
+	xSize, ySize := cfg.Width, cfg.Height
+	grid := newEmptyGrid(xSize, ySize)
+
+	cx := int(math.Floor(float64(xSize / 2)))
+	cy := int(math.Floor(float64(ySize / 2)))
+
+	for i := 0; i < cx; i++ {
+		for j := i; j < (ySize - i); j++ {
+			grid[j][i].Walls.Left = true
+			if (i - 1) >= 0 {
+				grid[j][i-1].Walls.Right = true
+			}
+			grid[j][xSize-1-i].Walls.Right = true
+			if (xSize - i) < xSize {
+				grid[j][xSize-i].Walls.Left = true
+			}
+		}
+	}
+
+	for j := 0; j < cy; j++ {
+		for i := j; i < (xSize - j); i++ {
+			grid[j][i].Walls.Top = true
+			if (j - 1) >= 0 {
+				grid[j-1][i].Walls.Bottom = true
+			}
+			grid[ySize-1-j][i].Walls.Bottom = true
+			if (ySize - j) < ySize {
+				grid[ySize-j][i].Walls.Top = true
+			}
+		}
+	}
+
+	for j := 1; j < cy; j++ {
+		i := j + rng.Intn(xSize-2*j)
+		grid[j][i].Walls.Top = false
+		if (j - 1) >= 0 {
+			grid[j-1][i].Walls.Bottom = false
+		}
+	}
+
+	z := mazelib.NewMazeFromRooms(grid)
+	PlaceIcarusAndTreasure(z, rng)
+	return z
+}
@@ -0,0 +1,147 @@
+// Copyright © 2015 Steve Francia <spf@spf13.com>.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+//
+
+package generators
+
+import (
+	"container/heap"
+	"math/rand"
+
+	"github.com/golangchallenge/gc6/mazelib"
+)
+
+func init() {
+	RegisterGenerator(weightedGenerator{})
+}
+
+// weightedGenerator lays a plain backtracker maze and then assigns every
+// room a random energy Cost, turning step count from the only thing worth
+// optimizing for into one of two (steps vs. energy spent). It precomputes
+// the cheapest possible Energy from start to treasure with Dijkstra so
+// Maze.Optimal can be used to score a solve's efficiency afterwards.
+type weightedGenerator struct{}
+
+func (weightedGenerator) Name() string { return "weighted" }
+
+// costMin and costMax bound the per-room energy cost. 1 keeps every edge
+// strictly positive (required for Dijkstra to terminate sensibly); the
+// upper bound just keeps a single expensive room from dwarfing the rest.
+const (
+	costMin = 1
+	costMax = 9
+)
+
+func (g weightedGenerator) Generate(cfg GenConfig, rng *rand.Rand) *mazelib.Maze {
+	grid := newFullGrid(cfg.Width, cfg.Height)
+	carveBacktracker(roomGrid(grid), cfg.Width, cfg.Height, rng)
+
+	for y := range grid {
+		for x := range grid[y] {
+			grid[y][x].Cost = costMin + rng.Intn(costMax-costMin+1)
+		}
+	}
+
+	z := mazelib.NewMazeFromRooms(grid)
+	PlaceIcarusAndTreasure(z, rng)
+
+	z.Optimal = dijkstraOptimalCost(z)
+
+	return z
+}
+
+// heapItem is one entry of the Dijkstra frontier: the accumulated cost to
+// reach coord.
+type heapItem struct {
+	coord mazelib.Coordinate
+	cost  int
+}
+
+// costHeap is a container/heap min-heap of heapItem ordered by cost.
+type costHeap []heapItem
+
+func (h costHeap) Len() int            { return len(h) }
+func (h costHeap) Less(i, j int) bool  { return h[i].cost < h[j].cost }
+func (h costHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *costHeap) Push(x interface{}) { *h = append(*h, x.(heapItem)) }
+func (h *costHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	item := old[n-1]
+	*h = old[:n-1]
+	return item
+}
+
+// dijkstraOptimalCost finds the cheapest possible Energy to walk m's start
+// to its treasure, entering each room paying its Room.Cost. It only looks
+// at non-walled neighbours, same as a real Icarus would have to.
+func dijkstraOptimalCost(m *mazelib.Maze) int {
+	startX, startY := m.Icarus()
+	treasureX, treasureY := m.Treasure()
+
+	dist := map[mazelib.Coordinate]int{}
+	start := mazelib.Coordinate{X: startX, Y: startY}
+	treasure := mazelib.Coordinate{X: treasureX, Y: treasureY}
+	dist[start] = 0
+
+	pq := &costHeap{{coord: start, cost: 0}}
+	heap.Init(pq)
+
+	for pq.Len() > 0 {
+		cur := heap.Pop(pq).(heapItem)
+
+		if cur.coord == treasure {
+			return cur.cost
+		}
+
+		if best, ok := dist[cur.coord]; ok && cur.cost > best {
+			continue
+		}
+
+		room, err := m.GetRoom(cur.coord.X, cur.coord.Y)
+		if err != nil {
+			continue
+		}
+
+		type step struct {
+			open bool
+			to   mazelib.Coordinate
+		}
+		for _, s := range []step{
+			{!room.Walls.Top, mazelib.Coordinate{X: cur.coord.X, Y: cur.coord.Y - 1}},
+			{!room.Walls.Right, mazelib.Coordinate{X: cur.coord.X + 1, Y: cur.coord.Y}},
+			{!room.Walls.Bottom, mazelib.Coordinate{X: cur.coord.X, Y: cur.coord.Y + 1}},
+			{!room.Walls.Left, mazelib.Coordinate{X: cur.coord.X - 1, Y: cur.coord.Y}},
+		} {
+			if !s.open {
+				continue
+			}
+
+			nr, err := m.GetRoom(s.to.X, s.to.Y)
+			if err != nil {
+				continue
+			}
+
+			next := cur.cost + nr.Cost
+			if best, ok := dist[s.to]; ok && best <= next {
+				continue
+			}
+
+			dist[s.to] = next
+			heap.Push(pq, heapItem{coord: s.to, cost: next})
+		}
+	}
+
+	return 0
+}
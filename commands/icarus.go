@@ -26,14 +26,140 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 	"math"
-	"math/rand"
-	"os"
 )
 
-type VirtualMaze struct {
-	Coords 		mazelib.Coordinate
-	Walls		mazelib.Survey
-	Visited		bool
+// Bitmap is a growable bit-per-index set, 8 indices per byte
+// (chunk_index := idx/8, bit := idx%8), used for the Visited flag of the
+// virtual maze so a sparse, far-flung exploration doesn't cost a full
+// VirtualMaze struct per cell.
+type Bitmap struct {
+	chunks []byte
+}
+
+func (b *Bitmap) grow(idx int) {
+	need := idx/8+1
+	if need>len(b.chunks) {
+		grown := make([]byte, need)
+		copy(grown, b.chunks)
+		b.chunks = grown
+	}
+}
+
+func (b *Bitmap) Get(idx int) bool {
+	chunkIndex := idx/8
+	if chunkIndex>=len(b.chunks) {
+		return false
+	}
+	bit := uint(idx%8)
+	return b.chunks[chunkIndex]&(1<<bit) != 0
+}
+
+func (b *Bitmap) Set(idx int) {
+	b.grow(idx)
+	chunkIndex := idx/8
+	bit := uint(idx%8)
+	b.chunks[chunkIndex] |= 1<<bit
+}
+
+// VirtualMap is Icarus's growing mental model of the maze: visited is a
+// Bitmap (one bit per coordsToInt index) and walls only holds an entry for
+// cells Icarus has actually probed, so the two structures together cost
+// nothing for the vast unexplored regions of a huge maze - unlike the old
+// []VirtualMaze, which allocated a full Survey+bool for every index up to
+// the largest one seen, 200 at a time.
+type VirtualMap struct {
+	walls   map[int]mazelib.Survey
+	visited Bitmap
+
+	// poiSeen dedupes AddPOI the same way visited dedupes SetVisited; pois
+	// keeps them in the order they were first stepped on.
+	poiSeen Bitmap
+	pois    []mazelib.Coordinate
+
+	// portals maps the coordsToInt index of a discovered portal endpoint to
+	// the coordinate of its pair. Learned one pair at a time: a Move that
+	// comes back with a non-nil *mazelib.Portal (Reply.Teleported) tells
+	// Icarus about both endpoints at once.
+	portals map[int]mazelib.Coordinate
+}
+
+func newVirtualMap() *VirtualMap {
+	return &VirtualMap{walls: make(map[int]mazelib.Survey)}
+}
+
+func (vm *VirtualMap) Visited(z int) bool { return vm.visited.Get(z) }
+func (vm *VirtualMap) SetVisited(z int)   { vm.visited.Set(z) }
+func (vm *VirtualMap) Walls(z int) mazelib.Survey { return vm.walls[z] }
+func (vm *VirtualMap) SetWalls(z int, s mazelib.Survey) { vm.walls[z] = s }
+
+// AddPOI records a waypoint Icarus has stepped onto, ignoring repeats of a
+// waypoint already seen.
+func (vm *VirtualMap) AddPOI(z int, c mazelib.Coordinate) {
+	if vm.poiSeen.Get(z) {
+		return
+	}
+	vm.poiSeen.Set(z)
+	vm.pois = append(vm.pois, c)
+}
+
+// POIs returns every waypoint discovered so far, in the order AddPOI saw them.
+func (vm *VirtualMap) POIs() []mazelib.Coordinate { return vm.pois }
+
+// IsPOI reports whether z was recorded as a waypoint by AddPOI.
+func (vm *VirtualMap) IsPOI(z int) bool { return vm.poiSeen.Get(z) }
+
+// AddPortal records a discovered portal pair in both directions, so
+// neighbors can transparently resolve a step into either endpoint as
+// landing on the other one instead of on the entry cell itself.
+func (vm *VirtualMap) AddPortal(a, b mazelib.Coordinate) {
+	if vm.portals == nil {
+		vm.portals = make(map[int]mazelib.Coordinate)
+	}
+	vm.portals[coordsToInt(a.X, a.Y)] = b
+	vm.portals[coordsToInt(b.X, b.Y)] = a
+}
+
+// neighbor is one edge out of a surveyed cell: press is the direction
+// (0=up, 1=right, 2=down, 3=left) that leads there, and z/c are the
+// landing cell's coordsToInt index and coordinate - already resolved
+// through any discovered portal, so callers never have to special-case a
+// teleport themselves.
+type neighbor struct {
+	press int
+	z     int
+	c     mazelib.Coordinate
+}
+
+// neighbors lists every direction open from c, per the walls surveyed at
+// its coordsToInt index. nearestUnvisited used to duplicate this
+// step-expansion inline; centralizing it here is also what lets it cross a
+// portal transparently, since only this one place needs to know portals
+// exist.
+func (vm *VirtualMap) neighbors(c mazelib.Coordinate) []neighbor {
+	walls := vm.Walls(coordsToInt(c.X, c.Y))
+	candidates := []struct {
+		open  bool
+		press int
+		x, y  int
+	}{
+		{!walls.Top, 0, c.X, c.Y - 1},
+		{!walls.Right, 1, c.X + 1, c.Y},
+		{!walls.Bottom, 2, c.X, c.Y + 1},
+		{!walls.Left, 3, c.X - 1, c.Y},
+	}
+
+	var ns []neighbor
+	for _, s := range candidates {
+		if !s.open {
+			continue
+		}
+		nc := mazelib.Coordinate{s.x, s.y}
+		if landing, ok := vm.portals[coordsToInt(s.x, s.y)]; ok {
+			nc = landing
+		}
+		ns = append(ns, neighbor{press: s.press, z: coordsToInt(nc.X, nc.Y), c: nc})
+	}
+	return ns
 }
 
 // Defining the icarus command.
@@ -70,37 +196,43 @@ func RunIcarus() {
 }
 
 // Make a call to the laybrinth server (daedalus) that icarus is ready to wake up
-func awake() mazelib.Survey {
+func awake() (mazelib.Survey, bool) {
 	contents, err := makeRequest("http://127.0.0.1:" + viper.GetString("port") + "/awake")
 	if err != nil {
 		fmt.Println(err)
 	}
 	r := ToReply(contents)
-	return r.Survey
+	return r.Survey, r.POI
 }
 
 // Make a call to the laybrinth server (daedalus)
 // to move Icarus a given direction
 // Will be used heavily by solveMaze
-func Move(direction string) (mazelib.Survey, error) {
+// The bool return reports whether the room Icarus moved into is a waypoint
+// (Reply.POI). The *mazelib.Portal return is Reply.Teleported: non-nil
+// whenever this move landed Icarus on a portal cell, letting callers learn
+// the pair and note that the room surveyed is the landed-on room, not the
+// one geometrically stepped into.
+func Move(direction string) (mazelib.Survey, bool, *mazelib.Portal, error) {
 	if direction == "left" || direction == "right" || direction == "up" || direction == "down" {
 
 		contents, err := makeRequest("http://127.0.0.1:" + viper.GetString("port") + "/move/" + direction)
 		if err != nil {
-			return mazelib.Survey{}, err
+			return mazelib.Survey{}, false, nil, err
 		}
 
 		rep := ToReply(contents)
 		if rep.Victory == true {
 			fmt.Println(rep.Message)
 			// os.Exit(1)
-			return rep.Survey, mazelib.ErrVictory
-		} else {
-			return rep.Survey, errors.New(rep.Message)
+			return rep.Survey, rep.POI, rep.Teleported, mazelib.ErrVictory
+		} else if rep.Error {
+			return rep.Survey, rep.POI, rep.Teleported, errors.New(rep.Message)
 		}
+		return rep.Survey, rep.POI, rep.Teleported, nil
 	}
 
-	return mazelib.Survey{}, errors.New("invalid direction")
+	return mazelib.Survey{}, false, nil, errors.New("invalid direction")
 }
 
 // utility function to wrap making requests to the daedalus server
@@ -125,327 +257,107 @@ func ToReply(in []byte) mazelib.Reply {
 }
 
 
-/**
- * Icarus will create a virtual map of the maze to keep track of the visited cells (visited)
- * Will also have a list the current path taken from the starting point (path)
- *
- */
-func backtrackerClassicIcarus() {
-	// Assume the size of the maze is unknown, even if for this challenge is fixed
-	mapSize := 200
-	pathIndex :=0
-	// Grow a 1D array is easier than 2D array
-	visited := make([]bool, mapSize)
-	path := make([]mazelib.Coordinate, viper.GetInt("max-steps"))
-	previousDirection := rand.Intn(4)
-	// Add 1 so it doesn't complain of unused variable (depends on the IA choosen it might not be used)
-	previousDirection++
-	z := coordsToInt(0, 0)
-
-
-	x := 0
-	y := 0
-	walls := awake();
-	err := errors.New("none")
-	visited[z] = true
-	path[z] = mazelib.Coordinate{0, 0}
-	for r:=0;r<viper.GetInt("max-steps");r++ { // It's a good idea to limit the step Icarus could take, so it doesn't walk forever, but it's already limited by Daedalus
-		goBack := true
-
-		//previous direction (default option)
-		nr := previousDirection
-		if viper.GetString("ia")=="classicrandom" {
-			//random decision making
-			nr = rand.Intn(4)
-		} else if viper.GetString("ia")=="classicmostlyright"{
-			// mostly right turns
-			nr = 0
-		}
-
-		for w:=0;w<4;w++ {
-
-			n := (nr+w)%4
-
-			if (n==0 && !walls.Top) || (n==1 && !walls.Right) || (n==2 && !walls.Bottom) || (n==3 && !walls.Left) {
-				nx := x
-				ny := y
-				switch(n) {
-				case 0:
-					ny = y-1
-					z = coordsToInt(x, y-1) // maze is a 1D array, so we need a function f(x,y) = z where z is unique foreach x,y pair
-					break
-				case 1:
-					nx = x+1
-					z = coordsToInt(x+1, y)
-					break
-				case 2:
-					ny = y+1
-					z = coordsToInt(x, y+1)
-					break
-				case 3:
-					nx = x-1
-					z = coordsToInt(x-1, y)
-					break
-				}
-				// we may want to extend our virtual maze
-				for ; z>=mapSize; {
-					visited, mapSize = extendVisited(visited, mapSize)
-				}
-
-				if !visited[z] {
-					visited[z] = true
-					walls, err = moveTo(n)
-					goBack = false
-					if err==mazelib.ErrVictory {
-						r = viper.GetInt("max-steps")+1 //break the outer loop (steps)
-						break
-					}
-					previousDirection = n
-					x = nx
-					y = ny
-					pathIndex++
-					path[pathIndex] = mazelib.Coordinate{x, y}
-
-					break
-				}
-			}
-		}
-		if goBack {
-
-			// FIND NEAREST non visited cell?
-
+// planStep is one precomputed move in a path: press is the moveTo direction
+// that produces it. Plain []mazelib.Coordinate stopped being enough once
+// portals could make consecutive stops non-adjacent - inferring a press
+// from the coordinate delta between them (as Explorer.Run's backtrack
+// branch used to) would silently take the wrong step, so
+// every path-producing function here now returns the press directions
+// explicitly instead of leaving them to be re-derived later. path[0] is
+// always a zero-value placeholder standing in for "already here", matching
+// the old convention of path[0] being the start coordinate that nothing
+// ever moved into.
+type planStep struct {
+	press int
+}
 
-			pathIndex--
-			if pathIndex<0 {
-				// This should never happens, it means we have to go back further than the starting cell
-				fmt.Println("No path to the treasure")
-				os.Exit(3)
-			}
-			coords := path[pathIndex]
-			if coords.Y<y {
-				walls, _ = moveTo(0)
-			} else if coords.X>x {
-				walls, _ = moveTo(1)
-			} else if coords.Y>y {
-				walls, _ = moveTo(2)
-			} else  {
-				walls, _ = moveTo(3)
-			}
-			x = coords.X
-			y = coords.Y
-		}
+// directionTo returns the single step direction from one cell to an
+// adjacent one. Explorer.Run's one-step ERROR fallback first asks
+// neighbors() for a press that actually lands on the target, and only
+// falls back to this if none does - which only happens when the two cells
+// genuinely are plain geometric neighbours.
+func directionTo(from, to mazelib.Coordinate) int {
+	switch {
+	case to.Y < from.Y:
+		return 0
+	case to.X > from.X:
+		return 1
+	case to.Y > from.Y:
+		return 2
+	default:
+		return 3
 	}
-
 }
 
-/**
- * Icarus will create a virtual map of the maze to keep track of the visited cells (visited)
- * Will also have a list the current path taken from the starting point (path)
- *
- */
-func backtrackerIcarus() {
-	// Assume the size of the maze is unknown, even if for this challenge is fixed
-	mapSize := 200
-	pathIndex :=0
-	// Grow a 1D array is easier than 2D array
-	virtual := make([]VirtualMaze, mapSize)
-	path := make([]mazelib.Coordinate, viper.GetInt("max-steps"))
-	previousDirection := rand.Intn(4)
-	// Add 1 so it doesn't complain of unused variable (depends on the IA choosen it might not be used)
-	previousDirection++
-	z := coordsToInt(0, 0)
-
-
-	x := 0
-	y := 0
-	walls := awake();
-	err := errors.New("none")
-	virtual[z].Visited = true
-	virtual[z].Walls = walls
-	path[z] = mazelib.Coordinate{0, 0}
-	for r:=0;r<viper.GetInt("max-steps");r++ { // It's a good idea to limit the step Icarus could take, so it doesn't walk forever, but it's already limited by Daedalus
-		goBack := true
-
-		//previous direction (default option)
-		nr := previousDirection
-		if viper.GetString("ia")=="random" {
-			//random decision making
-			nr = rand.Intn(4)
-		} else if viper.GetString("ia")=="mostlyright" {
-			// mostly right turns
-			nr = 0
+// nearestUnvisited does a BFS over the known virtual map starting at
+// path's last coordinate, expanding only through edges we've already
+// probed (maze.neighbors), and stops as soon as it pops a cell that's
+// unvisited (or hasn't been probed at all, i.e. falls outside maze's
+// current bounds). It used to be a recursive DFS that rejected cells
+// already in the current path, which is exponential on dense mazes -
+// BFS with a parent map keyed by coordsToInt gives the same "nearest"
+// guarantee in O(V+E) and never has to backtrack.
+func nearestUnvisited(maze *VirtualMap, path []mazelib.Coordinate, shortestLen int) ([]planStep, int) {
+	start := path[len(path)-1]
+	z0 := coordsToInt(start.X, start.Y)
+
+	coordOf := map[int]mazelib.Coordinate{z0: start}
+	parent := map[int]int{}
+	pressFrom := map[int]int{}
+	visited := map[int]bool{z0: true}
+	queue := []int{z0}
+
+	found := -1
+	for len(queue)>0 {
+		z := queue[0]
+		queue = queue[1:]
+
+		if z!=z0 && !maze.Visited(z) {
+			found = z
+			break
 		}
 
-		for w:=0;w<4;w++ {
-
-			n := (nr+w)%4
-
-			if (n==0 && !walls.Top) || (n==1 && !walls.Right) || (n==2 && !walls.Bottom) || (n==3 && !walls.Left) {
-				nx := x
-				ny := y
-				switch(n) {
-				case 0:
-					ny = y-1
-					z = coordsToInt(x, y-1) // maze is a 1D array, so we need a function f(x,y) = z where z is unique foreach x,y pair
-					break
-				case 1:
-					nx = x+1
-					z = coordsToInt(x+1, y)
-					break
-				case 2:
-					ny = y+1
-					z = coordsToInt(x, y+1)
-					break
-				case 3:
-					nx = x-1
-					z = coordsToInt(x-1, y)
-					break
-				}
-				// we may want to extend our virtual maze
-				for ; z>=mapSize; {
-					virtual, mapSize = extendVirtual(virtual, mapSize)
-				}
-
-				if !virtual[z].Visited {
-					virtual[z].Visited = true
-					walls, err = moveTo(n)
-					virtual[z].Walls = walls
-					virtual[z].Coords = mazelib.Coordinate{nx, ny}
-					goBack = false
-					if err==mazelib.ErrVictory {
-						r = viper.GetInt("max-steps")+1 //break the outer loop (steps)
-						break
-					}
-					previousDirection = n
-					x = nx
-					y = ny
-					pathIndex++
-					path[pathIndex] = mazelib.Coordinate{x, y}
-
-					break
-				}
-			}
+		if len(visited)>=shortestLen {
+			continue
 		}
-		if goBack {
-
-			// FIND NEAREST non visited cell?
-			nPath := make([]mazelib.Coordinate, 1, viper.GetInt("max-steps"))
-			nPath[0] = mazelib.Coordinate{x, y}
-			newPath, newLength := nearestUnvisited(virtual, nPath, viper.GetInt("max-steps"))
-
-			if newLength==viper.GetInt("max-steps") || newLength<2 {
-				// ERROR
-				newPath = make([]mazelib.Coordinate, 2, viper.GetInt("max-steps"))
-				newPath[1] = mazelib.Coordinate{path[pathIndex-1].X, path[pathIndex-1].Y}
-				newLength = 2
-			}
-
-			for p:=1;p<newLength;p++ {
-				if newPath[p].X<x {
-					walls, err = moveTo(3)
-					previousDirection = 3
-				} else if newPath[p].X>x {
-					walls, err = moveTo(1)
-					previousDirection = 1
-				} else if newPath[p].Y<y {
-					walls, err = moveTo(0)
-					previousDirection = 0
-				} else if newPath[p].Y>y {
-					walls, err = moveTo(2)
-					previousDirection = 2
-				}
-				x = newPath[p].X
-				y = newPath[p].Y
-				z = coordsToInt(x, y)
-				for ; z>=mapSize; {
-					virtual, mapSize = extendVirtual(virtual, mapSize)
-				}
-				virtual[z].Visited = true
-				virtual[z].Walls = walls
-
-				if err==mazelib.ErrVictory {
-					r = viper.GetInt("max-steps")+1 //break the outer loop (steps)
-					break
-				}
 
+		c := coordOf[z]
+		for _, nb := range maze.neighbors(c) {
+			if visited[nb.z] {
+				continue
 			}
-
+			visited[nb.z] = true
+			parent[nb.z] = z
+			pressFrom[nb.z] = nb.press
+			coordOf[nb.z] = nb.c
+			queue = append(queue, nb.z)
 		}
 	}
 
-}
+	if found==-1 {
+		return make([]planStep, 1), shortestLen
+	}
 
-func nearestUnvisited(maze []VirtualMaze, path []mazelib.Coordinate, shortestLen int) ([]mazelib.Coordinate, int) {
-	l := len(maze)
-	lp := len(path)
-	if lp>=shortestLen {
-		return make([]mazelib.Coordinate,1), shortestLen
+	ids := []int{found}
+	for z := found; z!=z0; {
+		z = parent[z]
+		ids = append(ids, z)
+	}
+	for i, j := 0, len(ids)-1; i<j; i, j = i+1, j-1 {
+		ids[i], ids[j] = ids[j], ids[i]
 	}
-	coords := path[lp-1]
-	z := coordsToInt(coords.X, coords.Y)
-	var tmpPath []mazelib.Coordinate
-	tmpLen := viper.GetInt("max-steps")
-	var shortestPath []mazelib.Coordinate
-	if z<l {
-		walls := maze[z].Walls
-
-		for i:=0;i<4;i++ {
-			tmpX := coords.X-1
-			tmpY := coords.Y
-			tmpWall := walls.Left
-			if i==0 {
-				tmpX = coords.X
-				tmpY = coords.Y-1
-				tmpWall = walls.Top
-			} else if i==1 {
-				tmpX = coords.X+1
-				tmpY = coords.Y
-				tmpWall = walls.Right
-			} else if i==2 {
-				tmpX = coords.X
-				tmpY = coords.Y+1
-				tmpWall = walls.Bottom
-			}
-			if !tmpWall && (lp==1 || (lp>1 && (tmpX!=path[lp-2].X || tmpY!=path[lp-2].Y))) {
-
-				found := false
-				for p:=(lp-1);p>=0;p-- {
-					if path[p].X==tmpX && path[p].Y==tmpY {
-						found = true
-						break
-					}
-				}
-
-				if !found {
-					z = coordsToInt(tmpX, tmpY)
-					tmpPath = path[0 : lp+1]
-					tmpPath[lp] = mazelib.Coordinate{tmpX, tmpY}
-					//fmt.Println("ADD CELL", tmpX, tmpY, maze[z].Walls, walls, tmpPath)
-					tmpLen = lp+1
-
-					if z<l && maze[z].Visited && tmpLen<shortestLen {
-						tmpPath, tmpLen = nearestUnvisited(maze, tmpPath, shortestLen)
-					}
-
-					if tmpLen<shortestLen {
-						shortestLen = tmpLen
-						shortestPath = make([]mazelib.Coordinate, tmpLen) //tmpPath[0:tmpLen]
-						copy(shortestPath, tmpPath)
-					}
-				}
-			}
-		}
 
-	} else {
-		return path, lp
+	shortestPath := make([]planStep, len(ids))
+	for i := 1; i < len(ids); i++ {
+		shortestPath[i] = planStep{press: pressFrom[ids[i]]}
 	}
 
-	return shortestPath, shortestLen
+	return shortestPath, len(shortestPath)
 }
 
 
 // little wrapper as it's easier to work with int than strings for the directions
-func moveTo(n int) (mazelib.Survey, error) {
+func moveTo(n int) (mazelib.Survey, bool, *mazelib.Portal, error) {
 	if n==0 {
 		return Move("up")
 	} else if n==1 {
@@ -480,24 +392,18 @@ func coordsToInt(x, y int) int {
 	return z
 }
 
-func extendVisited(labyrinth []bool, size int) ([]bool, int) {
-	newSize := size+200
-	newLabyrinth := make([]bool, newSize)
-	copy(newLabyrinth, labyrinth)
-	return newLabyrinth, newSize
-}
-
-func extendVirtual(labyrinth []VirtualMaze, size int) ([]VirtualMaze, int) {
-	newSize := size+200
-	newLabyrinth := make([]VirtualMaze, newSize)
-	copy(newLabyrinth, labyrinth)
-	return newLabyrinth, newSize
-}
-
+// solveMaze runs a single Solver to completion against whatever maze
+// Daedalus is currently serving. There used to be an "astar" IA mode here
+// (removed in 65ff828) and a "tsp" mode that explored a maze, recorded its
+// waypoints, and Held-Karp'd the cheapest tour visiting all of them before
+// the treasure - but the tour was never actually executed, since the
+// explorer's own exploration already reaches the treasure (ending that
+// session) before the tour is even computed, and a tour solved from one
+// run's exploration can't be reused on the next: Daedalus serves a brand
+// new random maze - and so a new set of waypoints - on every /awake.
+// Removed for the same reason as astar: don't leave a compute-and-throw-
+// away mode in place. solverFor falls back to "samedirection" for "tsp"
+// the same as any other unrecognized --ia value now.
 func solveMaze() {
-	if viper.GetString("ia")=="classicrandom" || viper.GetString("ia")=="classicmostlyright" || viper.GetString("ia")=="classicsamedirection" {
-		backtrackerClassicIcarus()
-	} else {
-		backtrackerIcarus()
-	}
+	runSolver(solverFor(viper.GetString("ia")))
 }
@@ -0,0 +1,200 @@
+// Copyright © 2015 Steve Francia <spf@spf13.com>.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+//
+
+package commands
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/golangchallenge/gc6/mazelib"
+	"github.com/nsf/termbox-go"
+	"github.com/spf13/cobra"
+)
+
+// icarusPlayCmd is a subcommand of icarus, not the top-level play (which
+// builds and walks a maze entirely in-process): this one drives a real
+// Icarus session against a running Daedalus server over Move(), the same
+// call Explorer.Run makes, just one key press at a time instead of
+// automated. It builds up a VirtualMap exactly like Explorer does,
+// so the rendering code is showing the same "what Icarus has discovered so
+// far" model the automated IAs reason over.
+var icarusPlayCmd = &cobra.Command{
+	Use:   "play",
+	Short: "Drive Icarus by hand against a running Daedalus server",
+	Long: `play lets a human stand in for one of solveMaze()'s automated IA
+  modes: arrow keys call Move(), and the maze is rendered live as Icarus
+  discovers it - walls as box-drawing characters, visited rooms as '·',
+  undiscovered rooms left blank. Press q or Esc to quit.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		runIcarusPlay()
+	},
+}
+
+func init() {
+	icarusCmd.AddCommand(icarusPlayCmd)
+}
+
+func runIcarusPlay() {
+	if err := termbox.Init(); err != nil {
+		fmt.Println(err)
+		os.Exit(-1)
+	}
+	defer termbox.Close()
+
+	virtual := newVirtualMap()
+	x, y := 0, 0
+	minX, maxX, minY, maxY := 0, 0, 0, 0
+
+	walls, poi := awake()
+	z := coordsToInt(x, y)
+	virtual.SetVisited(z)
+	virtual.SetWalls(z, walls)
+	if poi {
+		virtual.AddPOI(z, mazelib.Coordinate{X: x, Y: y})
+	}
+
+	steps := 0
+	victory := false
+
+	draw := func() {
+		termbox.Clear(termbox.ColorDefault, termbox.ColorDefault)
+		drawIcarusPlayMaze(virtual, x, y, minX, maxX, minY, maxY)
+
+		status := fmt.Sprintf("Steps: %d   arrows to move, q to quit", steps)
+		if victory {
+			status = fmt.Sprintf("Victory in %d steps!  Press q to quit.", steps)
+		}
+		drawIcarusPlayStatus(0, 2*(maxY-minY)+3, status)
+		termbox.Flush()
+	}
+	draw()
+
+	for {
+		ev := termbox.PollEvent()
+		if ev.Type != termbox.EventKey {
+			continue
+		}
+		if ev.Key == termbox.KeyEsc || ev.Ch == 'q' {
+			return
+		}
+		if victory {
+			continue
+		}
+
+		var dir string
+		nx, ny := x, y
+		switch ev.Key {
+		case termbox.KeyArrowUp:
+			dir, ny = "up", y-1
+		case termbox.KeyArrowRight:
+			dir, nx = "right", x+1
+		case termbox.KeyArrowDown:
+			dir, ny = "down", y+1
+		case termbox.KeyArrowLeft:
+			dir, nx = "left", x-1
+		default:
+			continue
+		}
+
+		newWalls, newPOI, portal, err := Move(dir)
+		if err == nil || err == mazelib.ErrVictory {
+			steps++
+			if portal != nil {
+				virtual.AddPortal(portal.A, portal.B)
+				switch {
+				case portal.A.X == nx && portal.A.Y == ny:
+					nx, ny = portal.B.X, portal.B.Y
+				case portal.B.X == nx && portal.B.Y == ny:
+					nx, ny = portal.A.X, portal.A.Y
+				}
+			}
+			x, y = nx, ny
+			z = coordsToInt(x, y)
+			virtual.SetVisited(z)
+			virtual.SetWalls(z, newWalls)
+			if newPOI {
+				virtual.AddPOI(z, mazelib.Coordinate{X: x, Y: y})
+			}
+
+			if x < minX {
+				minX = x
+			}
+			if x > maxX {
+				maxX = x
+			}
+			if y < minY {
+				minY = y
+			}
+			if y > maxY {
+				maxY = y
+			}
+
+			if err == mazelib.ErrVictory {
+				victory = true
+			}
+		}
+
+		draw()
+	}
+}
+
+// drawIcarusPlayMaze renders the rectangle [minX,maxX] x [minY,maxY] of
+// virtual as a grid of 2x2 blocks, same layout as daedalus's drawMaze:
+// unvisited rooms are left blank (we don't know they even exist yet),
+// visited ones show '·', and walls we've actually probed are drawn as
+// box-drawing characters.
+func drawIcarusPlayMaze(virtual *VirtualMap, icarusX, icarusY, minX, maxX, minY, maxY int) {
+	for y := minY; y <= maxY; y++ {
+		for x := minX; x <= maxX; x++ {
+			z := coordsToInt(x, y)
+			if !virtual.Visited(z) {
+				continue
+			}
+
+			cx, cy := 2*(x-minX)+1, 2*(y-minY)+1
+			walls := virtual.Walls(z)
+
+			center := rune('·')
+			fg, bg := termbox.ColorDefault, termbox.ColorDefault
+			if virtual.IsPOI(z) {
+				center, fg = '◆', termbox.ColorMagenta
+			}
+			if x == icarusX && y == icarusY {
+				center, bg = '@', termbox.ColorYellow
+			}
+			termbox.SetCell(cx, cy, center, fg, bg)
+
+			if walls.Top {
+				termbox.SetCell(cx, cy-1, '─', termbox.ColorDefault, termbox.ColorDefault)
+			}
+			if walls.Left {
+				termbox.SetCell(cx-1, cy, '│', termbox.ColorDefault, termbox.ColorDefault)
+			}
+			if walls.Bottom {
+				termbox.SetCell(cx, cy+1, '─', termbox.ColorDefault, termbox.ColorDefault)
+			}
+			if walls.Right {
+				termbox.SetCell(cx+1, cy, '│', termbox.ColorDefault, termbox.ColorDefault)
+			}
+		}
+	}
+}
+
+func drawIcarusPlayStatus(x, y int, s string) {
+	for i, r := range s {
+		termbox.SetCell(x+i, y, r, termbox.ColorDefault, termbox.ColorDefault)
+	}
+}
@@ -0,0 +1,313 @@
+// Copyright © 2015 Steve Francia <spf@spf13.com>.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+//
+
+package commands
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/golangchallenge/gc6/mazelib"
+	"github.com/spf13/cobra"
+)
+
+// liveCmd is a top-level sibling of daedalus/icarus, not a daedalus
+// subcommand like playCmd (commands/play.go): it reuses the same buildMaze
+// pipeline and Maze, but renders with raw ANSI escape codes and a live HUD
+// instead of termbox's full-screen viewer, closer to a simple arcade game
+// than a debugging tool.
+var liveCmd = &cobra.Command{
+	Use:   "play",
+	Short: "Play the maze live in your terminal with a real-time HUD",
+	Long: `play turns the module into a single-player game: Icarus is driven
+  with the arrow keys, the maze redraws in place using ANSI cursor control
+  instead of clearing the screen, and a ticking HUD tracks elapsed time and
+  score. Press q or Ctrl-C to quit.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		runLiveGame()
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(liveCmd)
+}
+
+// Background colors for the ANSI renderer. Plain SGR codes rather than
+// 256-color ones, so this looks reasonable even on old terminals.
+const (
+	ansiReset        = "\x1b[0m"
+	ansiBgWall       = "\x1b[100m" // bright black
+	ansiBgStart      = "\x1b[42m"  // green
+	ansiBgTreasure   = "\x1b[43m"  // yellow
+	ansiBgIcarus     = "\x1b[44m"  // blue
+	ansiClearScreen  = "\x1b[2J"
+	ansiCursorToHome = "\x1b[H"
+)
+
+// liveCell is one character cell of the rendered maze: its background color
+// (empty for "no color") and the rune drawn in it.
+type liveCell struct {
+	bg string
+	ch rune
+}
+
+// liveKey is a single decoded input event from readKeys.
+type liveKey int
+
+const (
+	keyNone liveKey = iota
+	keyUp
+	keyDown
+	keyLeft
+	keyRight
+	keyQuit
+)
+
+func runLiveGame() {
+	m := buildMaze()
+
+	restore, err := enableRawMode()
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(-1)
+	}
+	defer restore()
+
+	fmt.Print(ansiClearScreen, ansiCursorToHome)
+	defer fmt.Print(ansiReset, ansiClearScreen, ansiCursorToHome)
+
+	grid := newLiveGrid(m)
+	start := time.Now()
+	victory := false
+
+	drawLiveMaze(m, grid)
+	drawLiveHUD(m, start, victory)
+
+	keys := readKeys(os.Stdin)
+	ticker := time.NewTicker(500 * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case k, ok := <-keys:
+			if !ok || k == keyQuit {
+				drawLiveStatusLine(m, fmt.Sprintf("Quit after %d steps and %s.", m.StepsTaken, time.Since(start).Round(time.Second)))
+				return
+			}
+
+			if victory {
+				continue
+			}
+
+			var moveErr error
+			switch k {
+			case keyUp:
+				moveErr = m.MoveUp()
+			case keyDown:
+				moveErr = m.MoveDown()
+			case keyLeft:
+				moveErr = m.MoveLeft()
+			case keyRight:
+				moveErr = m.MoveRight()
+			default:
+				continue
+			}
+
+			if moveErr == nil {
+				if _, lookErr := m.LookAround(); lookErr == mazelib.ErrVictory {
+					victory = true
+				}
+			}
+
+			drawLiveMaze(m, grid)
+			drawLiveHUD(m, start, victory)
+			if victory {
+				drawLiveStatusLine(m, fmt.Sprintf("Escaped! Score: %d - press q to quit.", liveScore(m, time.Since(start))))
+			}
+
+		case <-ticker.C:
+			drawLiveHUD(m, start, victory)
+		}
+	}
+}
+
+// newLiveGrid allocates the zero-valued "last drawn" state for every cell of
+// m's block layout (the same 2x2-block-per-room grid the text fixture
+// format and play's drawMaze use), so the very first drawLiveMaze call paints the
+// whole maze.
+func newLiveGrid(m *Maze) [][]liveCell {
+	grid := make([][]liveCell, 2*m.Height()+1)
+	for i := range grid {
+		grid[i] = make([]liveCell, 2*m.Width()+1)
+	}
+	return grid
+}
+
+// drawLiveMaze walks every room of m and repaints only the cells of grid
+// whose color or glyph changed since the last call - the parallel rendering
+// path to MazeString, trading one big string for a stream of small
+// cursor-addressed writes.
+func drawLiveMaze(m *Maze, grid [][]liveCell) {
+	ix, iy := m.Icarus()
+
+	for y := 0; y < m.Height(); y++ {
+		for x := 0; x < m.Width(); x++ {
+			room, err := m.GetRoom(x, y)
+			if err != nil {
+				continue
+			}
+			cx, cy := 2*x+1, 2*y+1
+
+			center := liveCell{ch: ' '}
+			switch {
+			case x == ix && y == iy:
+				center.bg = ansiBgIcarus
+			case room.Treasure:
+				center.bg = ansiBgTreasure
+			case room.Start:
+				center.bg = ansiBgStart
+			}
+			putLiveCell(grid, cy, cx, center)
+
+			putLiveCell(grid, cy-1, cx, wallOrOpen(room.Walls.Top))
+			putLiveCell(grid, cy+1, cx, wallOrOpen(room.Walls.Bottom))
+			putLiveCell(grid, cy, cx-1, wallOrOpen(room.Walls.Left))
+			putLiveCell(grid, cy, cx+1, wallOrOpen(room.Walls.Right))
+
+			corner := liveCell{ch: ' ', bg: ansiBgWall}
+			putLiveCell(grid, cy-1, cx-1, corner)
+			putLiveCell(grid, cy-1, cx+1, corner)
+			putLiveCell(grid, cy+1, cx-1, corner)
+			putLiveCell(grid, cy+1, cx+1, corner)
+		}
+	}
+}
+
+func wallOrOpen(walled bool) liveCell {
+	if walled {
+		return liveCell{ch: ' ', bg: ansiBgWall}
+	}
+	return liveCell{ch: ' '}
+}
+
+// putLiveCell repaints (row, col) only if cell differs from what's already
+// there, moving the cursor there with a direct escape code rather than
+// reprinting anything above or below it.
+func putLiveCell(grid [][]liveCell, row, col int, cell liveCell) {
+	if grid[row][col] == cell {
+		return
+	}
+	grid[row][col] = cell
+	fmt.Printf("\x1b[%d;%dH%s%c%s", row+1, col+1, cell.bg, cell.ch, ansiReset)
+}
+
+// drawLiveHUD repaints the status line below the maze with the current step
+// count and elapsed time.
+func drawLiveHUD(m *Maze, start time.Time, victory bool) {
+	if victory {
+		return
+	}
+	elapsed := time.Since(start).Round(time.Second)
+	drawLiveStatusLine(m, fmt.Sprintf("Steps: %-4d  Elapsed: %-8s  arrows to move, q to quit", m.StepsTaken, elapsed))
+}
+
+func drawLiveStatusLine(m *Maze, s string) {
+	fmt.Printf("\x1b[%d;0H\x1b[2K%s\n", 2*m.Height()+2, s)
+}
+
+// liveScore rewards finishing in fewer steps and less time, floored at 0 so
+// a slow, wandering solve doesn't show a negative number.
+func liveScore(m *Maze, elapsed time.Duration) int {
+	score := 1000 - m.StepsTaken*5 - int(elapsed.Seconds())*2
+	if score < 0 {
+		score = 0
+	}
+	return score
+}
+
+// enableRawMode puts the controlling terminal into cbreak mode (read key by
+// key, no local echo) via stty, and returns a restore func that puts it
+// back. Shelling out to stty avoids pulling in a terminal-handling
+// dependency for what's otherwise a handful of ioctls.
+func enableRawMode() (restore func(), err error) {
+	saved, err := exec.Command("stty", "-F", "/dev/tty", "-g").Output()
+	if err != nil {
+		return nil, fmt.Errorf("play: %s", err)
+	}
+
+	if err := exec.Command("stty", "-F", "/dev/tty", "cbreak", "-echo").Run(); err != nil {
+		return nil, fmt.Errorf("play: %s", err)
+	}
+
+	state := strings.TrimSpace(string(saved))
+	return func() {
+		exec.Command("stty", "-F", "/dev/tty", state).Run()
+	}, nil
+}
+
+// readKeys decodes r as a stream of arrow-key and quit events and emits them
+// on a channel, so the game loop can select between key events and its HUD
+// ticker instead of blocking on input.
+func readKeys(r io.Reader) <-chan liveKey {
+	keys := make(chan liveKey)
+
+	go func() {
+		defer close(keys)
+		br := bufio.NewReader(r)
+
+		for {
+			b, err := br.ReadByte()
+			if err != nil {
+				return
+			}
+
+			switch b {
+			case 'q', 'Q', 3: // 3 is Ctrl-C
+				keys <- keyQuit
+			case 27: // ESC: might be the start of an arrow-key sequence
+				b2, err := br.ReadByte()
+				if err != nil {
+					return
+				}
+				if b2 != '[' {
+					keys <- keyQuit
+					continue
+				}
+
+				b3, err := br.ReadByte()
+				if err != nil {
+					return
+				}
+				switch b3 {
+				case 'A':
+					keys <- keyUp
+				case 'B':
+					keys <- keyDown
+				case 'C':
+					keys <- keyRight
+				case 'D':
+					keys <- keyLeft
+				}
+			}
+		}
+	}()
+
+	return keys
+}
@@ -0,0 +1,173 @@
+// Copyright © 2015 Steve Francia <spf@spf13.com>.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+//
+
+package commands
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/golangchallenge/gc6/mazelib"
+	"github.com/nsf/termbox-go"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// playCmd opens a freshly generated maze in an interactive terminal viewer
+// instead of starting the HTTP server, so a generator's output can be
+// sanity-checked by eye without needing a real Icarus client.
+var playCmd = &cobra.Command{
+	Use:   "play",
+	Short: "Walk the generated maze yourself in a terminal viewer",
+	Long: `play generates a maze the same way the server would and lets you
+  drive Icarus through it with the arrow keys, rendering walls and your
+  current position live. Press q or Esc to quit.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		runPlay()
+	},
+}
+
+func init() {
+	playCmd.Flags().Bool("reveal", false, "draw the whole maze instead of only the rooms Icarus has visited")
+	viper.BindPFlag("reveal", playCmd.Flags().Lookup("reveal"))
+	daedalusCmd.AddCommand(playCmd)
+}
+
+func runPlay() {
+	m := buildMaze()
+
+	if err := termbox.Init(); err != nil {
+		fmt.Println(err)
+		os.Exit(-1)
+	}
+	defer termbox.Close()
+
+	reveal := viper.GetBool("reveal")
+	seen := map[mazelib.Coordinate]bool{}
+	victory := false
+
+	markSeen := func() {
+		x, y := m.Icarus()
+		seen[mazelib.Coordinate{X: x, Y: y}] = true
+	}
+	markSeen()
+
+	draw := func() {
+		termbox.Clear(termbox.ColorDefault, termbox.ColorDefault)
+		drawMaze(m, seen, reveal)
+
+		status := fmt.Sprintf("Steps: %d   arrows to move, q to quit", m.StepsTaken)
+		if victory {
+			status = fmt.Sprintf("Victory in %d steps!  Press q to quit.", m.StepsTaken)
+		}
+		drawString(0, 2*m.Height()+1, status)
+
+		termbox.Flush()
+	}
+	draw()
+
+	for {
+		ev := termbox.PollEvent()
+		if ev.Type != termbox.EventKey {
+			continue
+		}
+
+		if ev.Key == termbox.KeyEsc || ev.Ch == 'q' {
+			return
+		}
+
+		if victory {
+			continue
+		}
+
+		var err error
+		switch ev.Key {
+		case termbox.KeyArrowLeft:
+			err = m.MoveLeft()
+		case termbox.KeyArrowRight:
+			err = m.MoveRight()
+		case termbox.KeyArrowUp:
+			err = m.MoveUp()
+		case termbox.KeyArrowDown:
+			err = m.MoveDown()
+		default:
+			continue
+		}
+
+		if err == nil {
+			if _, e := m.LookAround(); e == mazelib.ErrVictory {
+				victory = true
+			}
+		}
+
+		markSeen()
+		draw()
+	}
+}
+
+// drawMaze renders m as a grid of 2x2 blocks, same layout as the text
+// fixture format in mazelib: a center cell plus its right wall and bottom
+// wall. Rooms Icarus hasn't seen yet (per seen) are left blank unless
+// reveal is set.
+func drawMaze(m *Maze, seen map[mazelib.Coordinate]bool, reveal bool) {
+	ix, iy := m.Icarus()
+
+	for y := 0; y < m.Height(); y++ {
+		for x := 0; x < m.Width(); x++ {
+			if !reveal && !seen[mazelib.Coordinate{X: x, Y: y}] {
+				continue
+			}
+
+			room, err := m.GetRoom(x, y)
+			if err != nil {
+				continue
+			}
+
+			cx, cy := 2*x+1, 2*y+1
+
+			center := rune(' ')
+			fg, bg := termbox.ColorDefault, termbox.ColorDefault
+			switch {
+			case x == ix && y == iy:
+				center = '@'
+				bg = termbox.ColorYellow
+			case room.Treasure:
+				center, fg = 'T', termbox.ColorGreen
+			case room.Start:
+				center = 'S'
+			}
+			termbox.SetCell(cx, cy, center, fg, bg)
+
+			if room.Walls.Top {
+				termbox.SetCell(cx, cy-1, '#', termbox.ColorDefault, termbox.ColorDefault)
+			}
+			if room.Walls.Left {
+				termbox.SetCell(cx-1, cy, '#', termbox.ColorDefault, termbox.ColorDefault)
+			}
+			if room.Walls.Bottom {
+				termbox.SetCell(cx, cy+1, '#', termbox.ColorDefault, termbox.ColorDefault)
+			}
+			if room.Walls.Right {
+				termbox.SetCell(cx+1, cy, '#', termbox.ColorDefault, termbox.ColorDefault)
+			}
+		}
+	}
+}
+
+func drawString(x, y int, s string) {
+	for i, r := range s {
+		termbox.SetCell(x+i, y, r, termbox.ColorDefault, termbox.ColorDefault)
+	}
+}
@@ -0,0 +1,83 @@
+// Copyright © 2015 Steve Francia <spf@spf13.com>.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+//
+
+package commands
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/golangchallenge/gc6/commands/generators"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// renderCmd generates a single maze and writes it out as a picture of
+// itself - unicode art by default, or a PNG via --render=png. Unlike dump,
+// this is for looking at the maze, not reloading it later.
+var renderCmd = &cobra.Command{
+	Use:   "render",
+	Short: "Generate a maze and render it as unicode art or a PNG image",
+	Run: func(cmd *cobra.Command, args []string) {
+		runRender(cmd)
+	},
+}
+
+func init() {
+	renderCmd.Flags().String("generator", "", "generator to use, defaults to the same selection buildMaze() would make")
+	renderCmd.Flags().String("render", "ascii", "'ascii' for unicode art, or 'png' for an image")
+	renderCmd.Flags().String("out", "maze.png", "file to write the rendered maze to")
+	renderCmd.Flags().Int("cell-px", 24, "pixel size of one maze cell, for --render=png")
+	daedalusCmd.AddCommand(renderCmd)
+}
+
+func runRender(cmd *cobra.Command) {
+	rng := newMazeRand()
+
+	name, _ := cmd.Flags().GetString("generator")
+	g := resolveGenerator(name, rng)
+
+	cfg := generators.GenConfig{
+		Width:  viper.GetInt("width"),
+		Height: viper.GetInt("height"),
+	}
+	m := g.Generate(cfg, rng)
+
+	out, _ := cmd.Flags().GetString("out")
+	format, _ := cmd.Flags().GetString("render")
+
+	f, err := os.Create(out)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(-1)
+	}
+	defer f.Close()
+
+	switch format {
+	case "png":
+		cellPx, _ := cmd.Flags().GetInt("cell-px")
+		if err := MazePNG(m, cellPx, f); err != nil {
+			fmt.Println(err)
+			os.Exit(-1)
+		}
+	default:
+		if _, err := f.WriteString(MazeString(m)); err != nil {
+			fmt.Println(err)
+			os.Exit(-1)
+		}
+	}
+
+	fmt.Printf("wrote %dx%d maze to %s\n", m.Width(), m.Height(), out)
+}
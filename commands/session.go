@@ -0,0 +1,180 @@
+// Copyright © 2015 Steve Francia <spf@spf13.com>.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+//
+
+package commands
+
+import (
+	cryptorand "crypto/rand"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/golangchallenge/gc6/mazelib"
+)
+
+// sessionCookie is the cookie name; sessionHeader is the header Icarus can
+// use instead of a cookie (handy for non-browser clients).
+const sessionCookie = "session"
+const sessionHeader = "X-Session-Id"
+
+// session pairs a single client's maze with its own solve history, so many
+// Icarus clients can hit one Daedalus at once without stepping on each
+// other.
+type session struct {
+	maze     *Maze
+	scores   []int
+	energy   []int
+	lastSeen time.Time
+}
+
+// SessionManager keeps one *Maze per client, garbage collecting sessions
+// that have gone idle for longer than idleTTL.
+type SessionManager struct {
+	mu       sync.RWMutex
+	sessions map[string]*session
+	idleTTL  time.Duration
+}
+
+// NewSessionManager starts a SessionManager along with its background idle
+// sweep; stop is driven entirely by process exit, same as the rest of
+// Daedalus.
+func NewSessionManager(idleTTL time.Duration) *SessionManager {
+	sm := &SessionManager{
+		sessions: make(map[string]*session),
+		idleTTL:  idleTTL,
+	}
+	go sm.gc()
+	return sm
+}
+
+func (sm *SessionManager) gc() {
+	ticker := time.NewTicker(sm.idleTTL)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		cutoff := time.Now().Add(-sm.idleTTL)
+
+		sm.mu.Lock()
+		for id, s := range sm.sessions {
+			if s.lastSeen.Before(cutoff) {
+				delete(sm.sessions, id)
+			}
+		}
+		sm.mu.Unlock()
+	}
+}
+
+// New registers a freshly generated maze under a new session ID.
+func (sm *SessionManager) New(m *Maze) string {
+	id := newSessionID()
+
+	sm.mu.Lock()
+	sm.sessions[id] = &session{maze: m, lastSeen: time.Now()}
+	sm.mu.Unlock()
+
+	return id
+}
+
+// Get returns the maze for a session ID, bumping its idle timer.
+func (sm *SessionManager) Get(id string) (*Maze, bool) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	s, ok := sm.sessions[id]
+	if !ok {
+		return nil, false
+	}
+
+	s.lastSeen = time.Now()
+	return s.maze, true
+}
+
+// End retires a session's maze, freeing it up immediately instead of
+// waiting for the idle sweep. Reports whether id was a known session.
+func (sm *SessionManager) End(id string) bool {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	if _, ok := sm.sessions[id]; !ok {
+		return false
+	}
+
+	delete(sm.sessions, id)
+	return true
+}
+
+// RecordScore appends a solve's step count and energy spent to a session's
+// own history.
+func (sm *SessionManager) RecordScore(id string, steps, energy int) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	if s, ok := sm.sessions[id]; ok {
+		s.scores = append(s.scores, steps)
+		s.energy = append(s.energy, energy)
+	}
+}
+
+// Stats reports how many times a session has solved its maze, and the
+// average step count and energy spent across those solves.
+func (sm *SessionManager) Stats(id string) (solved, avgSteps, avgEnergy int, ok bool) {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+
+	s, ok := sm.sessions[id]
+	if !ok {
+		return 0, 0, 0, false
+	}
+
+	return len(s.scores), mazelib.AvgScores(s.scores), mazelib.AvgScores(s.energy), true
+}
+
+// newSessionID generates a random (v4) UUID.
+func newSessionID() string {
+	b := make([]byte, 16)
+	cryptorand.Read(b)
+
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// scoreBoard is the process-wide tally across every session, kept around so
+// printResults (and the Ctrl+C handler) still gets to report an overall
+// average.
+type scoreBoard struct {
+	mu     sync.Mutex
+	scores []int
+	energy []int
+}
+
+func (b *scoreBoard) record(steps, energy int) {
+	b.mu.Lock()
+	b.scores = append(b.scores, steps)
+	b.energy = append(b.energy, energy)
+	b.mu.Unlock()
+}
+
+func (b *scoreBoard) snapshot() (scores, energy []int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	scores = make([]int, len(b.scores))
+	copy(scores, b.scores)
+	energy = make([]int, len(b.energy))
+	copy(energy, b.energy)
+	return scores, energy
+}
@@ -0,0 +1,310 @@
+// Copyright © 2015 Steve Francia <spf@spf13.com>.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+//
+
+package commands
+
+import (
+	"errors"
+	"math/rand"
+
+	"github.com/golangchallenge/gc6/mazelib"
+	"github.com/spf13/viper"
+)
+
+// Solver is a pluggable per-move IA strategy: given where Icarus now stands
+// and the survey of that room, it picks which of the four directions
+// (0=up, 1=right, 2=down, 3=left) Explorer should try first. Explorer owns
+// the actual backtracking - rotating through the remaining directions if
+// the preferred one is walled or already visited, and falling back to
+// nearestUnvisited's BFS once none of the four lead anywhere new - so a
+// Solver only ever has to answer "which way would you try first from here".
+type Solver interface {
+	// Init is called once, right when Icarus wakes up, with the survey of
+	// the starting room.
+	Init(start mazelib.Survey)
+	// Next is called once per step, with pos reflecting wherever Explorer
+	// actually ended up after the previous step (a forward move or a
+	// multi-hop backtrack alike), so a Solver that cares which way it just
+	// came from can derive that itself by comparing pos across calls.
+	Next(pos mazelib.Coordinate, walls mazelib.Survey) (direction int, err error)
+	// OnVictory is called once Daedalus reports mazelib.ErrVictory.
+	OnVictory()
+}
+
+// solvers holds every Solver factory registered by RegisterSolver, keyed by
+// the --ia config value that selects it.
+var solvers = map[string]func() Solver{}
+
+// RegisterSolver makes a Solver available under the given --ia name. Solver
+// implementations register themselves from an init() in their own file,
+// the same convention generators.RegisterGenerator and solver.Register use.
+func RegisterSolver(name string, factory func() Solver) {
+	solvers[name] = factory
+}
+
+// solverFor looks up the Solver registered for the given --ia value,
+// defaulting to "samedirection" (backtrackerIcarus's old default) for
+// anything unrecognized.
+func solverFor(ia string) Solver {
+	if factory, ok := solvers[ia]; ok {
+		return factory()
+	}
+	return solvers["samedirection"]()
+}
+
+func init() {
+	RegisterSolver("random", func() Solver { return &randomSolver{} })
+	RegisterSolver("classicrandom", func() Solver { return &randomSolver{} })
+	RegisterSolver("mostlyright", func() Solver { return &mostlyRightSolver{} })
+	RegisterSolver("classicmostlyright", func() Solver { return &mostlyRightSolver{} })
+	RegisterSolver("samedirection", func() Solver { return &sameDirectionSolver{} })
+	RegisterSolver("classicsamedirection", func() Solver { return &sameDirectionSolver{} })
+}
+
+// randomSolver picks a uniformly random direction to try first every step.
+// "random" and "classicrandom" used to be the same heuristic duplicated
+// across backtrackerIcarus and backtrackerClassicIcarus; both names are
+// kept registered, pointing at this one type, so existing --ia values keep
+// working now that Explorer has unified their bookkeeping.
+type randomSolver struct{}
+
+func (*randomSolver) Init(mazelib.Survey) {}
+
+func (*randomSolver) Next(mazelib.Coordinate, mazelib.Survey) (int, error) {
+	return rand.Intn(4), nil
+}
+
+func (*randomSolver) OnVictory() {}
+
+// mostlyRightSolver always tries up first, then right, then down, then
+// left - this project's original "mostly right" rotation order. Registered
+// under both "mostlyright" and "classicmostlyright" for the same reason as
+// randomSolver.
+type mostlyRightSolver struct{}
+
+func (*mostlyRightSolver) Init(mazelib.Survey) {}
+
+func (*mostlyRightSolver) Next(mazelib.Coordinate, mazelib.Survey) (int, error) {
+	return 0, nil
+}
+
+func (*mostlyRightSolver) OnVictory() {}
+
+// sameDirectionSolver keeps trying the direction it last actually moved in,
+// picked up from the change in pos between calls, falling back to a random
+// start. It's also the default Solver when --ia isn't one of the other
+// registered names, matching backtrackerIcarus's old behaviour when "ia"
+// was left unset.
+type sameDirectionSolver struct {
+	have bool
+	last mazelib.Coordinate
+	dir  int
+}
+
+func (s *sameDirectionSolver) Init(mazelib.Survey) {
+	s.dir = rand.Intn(4)
+}
+
+func (s *sameDirectionSolver) Next(pos mazelib.Coordinate, walls mazelib.Survey) (int, error) {
+	if s.have {
+		switch {
+		case pos.Y < s.last.Y:
+			s.dir = 0
+		case pos.X > s.last.X:
+			s.dir = 1
+		case pos.Y > s.last.Y:
+			s.dir = 2
+		case pos.X < s.last.X:
+			s.dir = 3
+		}
+	}
+	s.have = true
+	s.last = pos
+	return s.dir, nil
+}
+
+func (*sameDirectionSolver) OnVictory() {}
+
+// Explorer owns the virtual-map/path bookkeeping every backtracking IA
+// needs - waking Icarus up, remembering which rooms have been visited and
+// what their walls look like, and falling back to nearestUnvisited's BFS
+// once a Solver's preferred direction is walled or already explored - so a
+// Solver only has to decide which direction to try first.
+type Explorer struct {
+	virtual *VirtualMap
+}
+
+func newExplorer() *Explorer {
+	return &Explorer{virtual: newVirtualMap()}
+}
+
+// geometricStep returns the raw neighbour of (x, y) in the given press
+// direction, with no portal resolution applied.
+func geometricStep(x, y, press int) (int, int) {
+	switch press {
+	case 0:
+		return x, y - 1
+	case 1:
+		return x + 1, y
+	case 2:
+		return x, y + 1
+	default:
+		return x - 1, y
+	}
+}
+
+// landingOf resolves the cell Icarus actually ends up in after stepping
+// from (x, y) in the given direction, through any portal already known at
+// the geometric destination. Used both to peek before moving (can this
+// direction possibly lead somewhere new?) and by stepTo to resolve a move
+// through a portal that was already known before it; a portal discovered
+// by that very move is folded in by stepTo itself.
+func (e *Explorer) landingOf(x, y, press int) (int, int) {
+	gx, gy := geometricStep(x, y, press)
+	if landing, ok := e.virtual.portals[coordsToInt(gx, gy)]; ok {
+		return landing.X, landing.Y
+	}
+	return gx, gy
+}
+
+// stepTo presses direction from (x, y) via moveTo, and resolves the actual
+// landing coordinate: through any already-known portal before the move,
+// and - on first discovery, when moveTo hands back a non-nil
+// *mazelib.Portal - by registering the pair and re-resolving against the
+// raw geometric neighbour computed here, never against a coordinate that
+// might already have been portal-resolved (which would flip a known
+// portal's landing back to its entry on a later crossing).
+func (e *Explorer) stepTo(x, y, press int) (nx, ny int, walls mazelib.Survey, poi bool, err error) {
+	gx, gy := geometricStep(x, y, press)
+
+	var portal *mazelib.Portal
+	walls, poi, portal, err = moveTo(press)
+
+	nx, ny = e.landingOf(x, y, press)
+	if portal != nil {
+		e.virtual.AddPortal(portal.A, portal.B)
+		switch {
+		case portal.A.X == gx && portal.A.Y == gy:
+			nx, ny = portal.B.X, portal.B.Y
+		case portal.B.X == gx && portal.B.Y == gy:
+			nx, ny = portal.A.X, portal.A.Y
+		}
+	}
+	return nx, ny, walls, poi, err
+}
+
+// Run drives s through a full exploration of whatever maze Daedalus is
+// currently serving, until it reports mazelib.ErrVictory or max-steps runs
+// out, and returns the coordinate Explorer ended on and whether that was
+// the treasure.
+func (e *Explorer) Run(s Solver) (mazelib.Coordinate, bool) {
+	maxSteps := viper.GetInt("max-steps")
+	// path tracks every cell actually stood on, forward moves and reroute
+	// hops alike, so the ERROR fallback below always has the real previous
+	// position to retreat toward - a fixed maxSteps-sized array sized for
+	// "one entry per outer iteration" isn't enough once a single reroute
+	// can append more than one entry per iteration, so this grows instead.
+	path := make([]mazelib.Coordinate, 1, maxSteps)
+
+	x, y := 0, 0
+	z := coordsToInt(0, 0)
+	walls, poi := awake()
+	s.Init(walls)
+	e.virtual.SetVisited(z)
+	e.virtual.SetWalls(z, walls)
+	if poi {
+		e.virtual.AddPOI(z, mazelib.Coordinate{0, 0})
+	}
+	path[0] = mazelib.Coordinate{0, 0}
+
+	err := errors.New("none")
+	for r := 0; r < maxSteps; r++ {
+		goBack := true
+
+		nr, _ := s.Next(mazelib.Coordinate{x, y}, walls)
+
+		for w := 0; w < 4; w++ {
+			n := (nr + w) % 4
+
+			if (n == 0 && !walls.Top) || (n == 1 && !walls.Right) || (n == 2 && !walls.Bottom) || (n == 3 && !walls.Left) {
+				peekX, peekY := e.landingOf(x, y, n)
+				if !e.virtual.Visited(coordsToInt(peekX, peekY)) {
+					var nx, ny int
+					nx, ny, walls, poi, err = e.stepTo(x, y, n)
+					z = coordsToInt(nx, ny)
+					e.virtual.SetVisited(z)
+					e.virtual.SetWalls(z, walls)
+					if poi {
+						e.virtual.AddPOI(z, mazelib.Coordinate{nx, ny})
+					}
+					goBack = false
+					if err == mazelib.ErrVictory {
+						s.OnVictory()
+						return mazelib.Coordinate{nx, ny}, true
+					}
+					x, y = nx, ny
+					path = append(path, mazelib.Coordinate{x, y})
+					break
+				}
+			}
+		}
+
+		if goBack {
+			nPath := make([]mazelib.Coordinate, 1, maxSteps)
+			nPath[0] = mazelib.Coordinate{x, y}
+			newPath, newLength := nearestUnvisited(e.virtual, nPath, maxSteps)
+
+			if newLength == maxSteps || newLength < 2 {
+				back := path[len(path)-2]
+				press := directionTo(mazelib.Coordinate{x, y}, back)
+				for _, nb := range e.virtual.neighbors(mazelib.Coordinate{x, y}) {
+					if nb.c == back {
+						press = nb.press
+						break
+					}
+				}
+				newPath = []planStep{{}, {press: press}}
+				newLength = 2
+			}
+
+			for p := 1; p < newLength; p++ {
+				var nx, ny int
+				nx, ny, walls, poi, err = e.stepTo(x, y, newPath[p].press)
+				x, y = nx, ny
+				path = append(path, mazelib.Coordinate{x, y})
+				z = coordsToInt(x, y)
+				e.virtual.SetVisited(z)
+				e.virtual.SetWalls(z, walls)
+				if poi {
+					e.virtual.AddPOI(z, mazelib.Coordinate{x, y})
+				}
+
+				if err == mazelib.ErrVictory {
+					s.OnVictory()
+					return mazelib.Coordinate{x, y}, true
+				}
+			}
+		}
+	}
+
+	return mazelib.Coordinate{x, y}, false
+}
+
+// runSolver runs a single Solver to completion against whatever maze
+// Daedalus is serving; this is solveMaze's default path, replacing the old
+// string-dispatched backtrackerClassicIcarus/backtrackerIcarus pair.
+func runSolver(s Solver) {
+	newExplorer().Run(s)
+}
@@ -0,0 +1,158 @@
+// Copyright © 2015 Steve Francia <spf@spf13.com>.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+//
+
+package mazelib
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math/rand"
+	"strings"
+)
+
+// LoadMazeFromText reads the natural ASCII-art form of a maze: one
+// character per Room, wallChar (e.g. '#') for a solid cell and pathChar
+// (e.g. ' ') for open floor, with each open cell's Walls derived by
+// comparing it against its four neighbors - a neighbor off the edge of the
+// grid counts as solid, same as a wallChar cell would. This is the
+// single-char-per-cell sibling to LoadMazeText's 2x2-block format, for
+// hand-drawing a maze without needing a dedicated wall row/column between
+// every pair of rooms. The 'S' and 'T' markers are optional here: if either
+// is missing, that room is placed randomly, the same way a generator's
+// PlaceIcarusAndTreasure would.
+func LoadMazeFromText(r io.Reader, pathChar, wallChar byte) (*Maze, error) {
+	grid, startX, startY, treasureX, treasureY, err := parseCellGrid(r, pathChar, wallChar)
+	if err != nil {
+		return nil, err
+	}
+
+	height, width := len(grid), len(grid[0])
+
+	if startX == -1 {
+		startX, startY = rand.Intn(width), rand.Intn(height)
+		for startX == treasureX && startY == treasureY {
+			startX, startY = rand.Intn(width), rand.Intn(height)
+		}
+	}
+	if treasureX == -1 {
+		treasureX, treasureY = rand.Intn(width), rand.Intn(height)
+		for treasureX == startX && treasureY == startY {
+			treasureX, treasureY = rand.Intn(width), rand.Intn(height)
+		}
+	}
+
+	m := NewMazeFromRooms(grid)
+	if err := m.SetStartPoint(startX, startY); err != nil {
+		return nil, err
+	}
+	if err := m.SetTreasure(treasureX, treasureY); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// parseCellGrid reads the grid LoadMazeFromText parses: one pathChar,
+// wallChar, startChar or treasureChar per Room, rather than parseRoomGrid's
+// 2x2 wall-block-per-Room layout. A wallChar cell becomes a Room walled in
+// on all four sides (it's never itself entered, only ever seen as a
+// neighbor); a pathChar/'S'/'T' cell's Walls are true on whichever sides
+// face a wallChar cell or the edge of the grid. startX/treasureX come back
+// -1 if 'S'/'T' wasn't present, left for the caller to decide what to do
+// about.
+func parseCellGrid(r io.Reader, pathChar, wallChar byte) (grid [][]Room, startX, startY, treasureX, treasureY int, err error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	var lines []string
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, 0, 0, 0, 0, err
+	}
+
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	if len(lines) == 0 {
+		return nil, 0, 0, 0, 0, fmt.Errorf("mazelib: text maze must have at least one row")
+	}
+
+	width := 0
+	for _, l := range lines {
+		if len(l) > width {
+			width = len(l)
+		}
+	}
+	if width == 0 {
+		return nil, 0, 0, 0, 0, fmt.Errorf("mazelib: text maze must have at least one column")
+	}
+	height := len(lines)
+
+	isWall := func(x, y int) bool {
+		if x < 0 || x >= width || y < 0 || y >= height {
+			return true
+		}
+		line := lines[y]
+		if x >= len(line) {
+			return true
+		}
+		return line[x] == wallChar
+	}
+
+	grid = make([][]Room, height)
+	for y := range grid {
+		grid[y] = make([]Room, width)
+	}
+
+	startX, startY, treasureX, treasureY = -1, -1, -1, -1
+
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			var c byte = wallChar
+			if x < len(lines[y]) {
+				c = lines[y][x]
+			}
+			if c != pathChar && c != wallChar && c != startChar && c != treasureChar {
+				return nil, 0, 0, 0, 0, fmt.Errorf("mazelib: text maze has unexpected character %q at row %d, column %d", c, y, x)
+			}
+
+			room := &grid[y][x]
+
+			if isWall(x, y) {
+				room.Walls.Top, room.Walls.Bottom = true, true
+				room.Walls.Left, room.Walls.Right = true, true
+				continue
+			}
+
+			room.Walls.Top = isWall(x, y-1)
+			room.Walls.Bottom = isWall(x, y+1)
+			room.Walls.Left = isWall(x-1, y)
+			room.Walls.Right = isWall(x+1, y)
+
+			switch lines[y][x] {
+			case startChar:
+				startX, startY = x, y
+			case treasureChar:
+				treasureX, treasureY = x, y
+			}
+		}
+	}
+
+	return grid, startX, startY, treasureX, treasureY, nil
+}
@@ -0,0 +1,360 @@
+// Copyright © 2015 Steve Francia <spf@spf13.com>.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+//
+
+package mazelib
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Maze is the grid-based implementation of MazeI that Daedalus serves and
+// the generators build. It used to live in the commands package, but enough
+// of the rest of the project (generators, loaders, solvers) needs to build
+// or walk a Maze without depending on commands that it makes more sense for
+// it to live here.
+type Maze struct {
+	rooms      [][]Room
+	start      Coordinate
+	end        Coordinate
+	icarus     Coordinate
+	StepsTaken int
+
+	// Portals indexed by name, and a parallel lookup by coordinate so a
+	// move can cheaply check "did I just step onto a portal".
+	Portals    map[string]*Portal
+	portalAt   map[Coordinate]*Portal
+	lastPortal *Portal
+	Level      int
+
+	// pois are waypoints registered with AddPOI that a multi-stop IA should
+	// visit before heading for the treasure. Unlike the treasure there can
+	// be any number of them.
+	pois []Coordinate
+
+	// Energy is the running total of Room.Cost paid entering rooms on a
+	// weighted maze; it stays 0 on mazes whose rooms don't set Cost.
+	Energy int
+	// Optimal is the cheapest possible Energy from start to treasure,
+	// precomputed by the generator with a Dijkstra pass so solutions can be
+	// scored by energy/Optimal instead of just step count.
+	Optimal int
+}
+
+// NewMaze creates a maze without any walls. Good starting point for
+// additive algorithms.
+func NewMaze(width, height int) *Maze {
+	z := &Maze{}
+
+	z.rooms = make([][]Room, height)
+	for y := 0; y < height; y++ {
+		z.rooms[y] = make([]Room, width)
+	}
+
+	return z
+}
+
+// NewFullMaze creates a maze with all walls up. Good starting point for
+// subtractive algorithms.
+func NewFullMaze(width, height int) *Maze {
+	z := NewMaze(width, height)
+
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			z.rooms[y][x].Walls = Survey{Top: true, Right: true, Bottom: true, Left: true}
+		}
+	}
+
+	return z
+}
+
+// NewMazeFromRooms wraps an already-built grid of rooms as a Maze. Callers
+// are expected to still call SetStartPoint/SetTreasure afterwards.
+func NewMazeFromRooms(rooms [][]Room) *Maze {
+	return &Maze{rooms: rooms}
+}
+
+// Return a room from the maze
+func (m *Maze) GetRoom(x, y int) (*Room, error) {
+	if x < 0 || y < 0 || x >= m.Width() || y >= m.Height() {
+		return &Room{}, errors.New("room outside of maze boundaries")
+	}
+
+	return &m.rooms[y][x], nil
+}
+
+func (m *Maze) Width() int  { return len(m.rooms[0]) }
+func (m *Maze) Height() int { return len(m.rooms) }
+
+// Return Icarus's current position
+func (m *Maze) Icarus() (x, y int) {
+	return m.icarus.X, m.icarus.Y
+}
+
+// Return the treasure's position
+func (m *Maze) Treasure() (x, y int) {
+	return m.end.X, m.end.Y
+}
+
+// Set the location where Icarus will awake
+func (m *Maze) SetStartPoint(x, y int) error {
+	r, err := m.GetRoom(x, y)
+
+	if err != nil {
+		return err
+	}
+
+	if r.Treasure {
+		return errors.New("can't start in the treasure")
+	}
+
+	r.Start = true
+	m.start = Coordinate{x, y}
+	m.icarus = Coordinate{x, y}
+	return nil
+}
+
+// Set the location of the treasure for a given maze
+func (m *Maze) SetTreasure(x, y int) error {
+	r, err := m.GetRoom(x, y)
+
+	if err != nil {
+		return err
+	}
+
+	if r.Start {
+		return errors.New("can't have the treasure at the start")
+	}
+
+	r.Treasure = true
+	m.end = Coordinate{x, y}
+	return nil
+}
+
+// AddPOI marks the room at x,y as a waypoint. A multi-stop IA is expected to
+// visit every registered waypoint before finishing at the treasure; Daedalus
+// itself doesn't enforce that, it just reports POI on the Reply for any room
+// that's been marked this way.
+func (m *Maze) AddPOI(x, y int) error {
+	r, err := m.GetRoom(x, y)
+	if err != nil {
+		return err
+	}
+
+	if r.Start || r.Treasure {
+		return errors.New("can't place a waypoint on the start or the treasure")
+	}
+
+	r.POI = true
+	m.pois = append(m.pois, Coordinate{x, y})
+	return nil
+}
+
+// POIs returns every waypoint coordinate registered with AddPOI.
+func (m *Maze) POIs() []Coordinate {
+	return m.pois
+}
+
+// AddPortal registers a named pair of rooms that teleport Icarus between
+// each other when he steps into either one. Neither end may sit on the
+// start or the treasure, since that would make those cells unreachable (or
+// trivial) in a way that isn't really a "maze".
+func (m *Maze) AddPortal(name string, a, b Coordinate) error {
+	for _, c := range []Coordinate{a, b} {
+		if (c.X == m.start.X && c.Y == m.start.Y) || (c.X == m.end.X && c.Y == m.end.Y) {
+			return errors.New("can't place a portal on the start or the treasure")
+		}
+	}
+
+	p := &Portal{Name: name, A: a, B: b}
+
+	if m.Portals == nil {
+		m.Portals = make(map[string]*Portal)
+		m.portalAt = make(map[Coordinate]*Portal)
+	}
+
+	m.Portals[name] = p
+	m.portalAt[a] = p
+	m.portalAt[b] = p
+	return nil
+}
+
+// teleport checks whether Icarus's current position sits on a portal and,
+// if so, moves him to the paired room. It does not re-run any wall checks:
+// the step that landed him here already happened, teleporting is simply
+// where that step ends up.
+func (m *Maze) teleport() {
+	m.lastPortal = nil
+
+	p, ok := m.portalAt[m.icarus]
+	if !ok {
+		return
+	}
+
+	dest := p.A
+	if dest == m.icarus {
+		dest = p.B
+	}
+
+	m.icarus = dest
+	m.lastPortal = p
+}
+
+// LastTeleport reports the portal Icarus stepped through on his last move,
+// or nil if that move didn't land on one.
+func (m *Maze) LastTeleport() *Portal {
+	return m.lastPortal
+}
+
+// Given Icarus's current location, Discover that room
+// Will return ErrVictory if Icarus is at the treasure.
+func (m *Maze) LookAround() (Survey, error) {
+	if m.end.X == m.icarus.X && m.end.Y == m.icarus.Y {
+		fmt.Printf("Victory achieved in %d steps \n", m.StepsTaken)
+		return Survey{}, ErrVictory
+	}
+
+	return m.Discover(m.icarus.X, m.icarus.Y)
+}
+
+// Given two points, survey the room.
+// Will return error if two points are outside of the maze
+func (m *Maze) Discover(x, y int) (Survey, error) {
+	r, err := m.GetRoom(x, y)
+	if err != nil {
+		return Survey{}, err
+	}
+
+	s := r.Walls
+
+	if !s.Top {
+		if nr, err := m.GetRoom(x, y-1); err == nil {
+			s.CostTop = nr.Cost
+		}
+	}
+	if !s.Right {
+		if nr, err := m.GetRoom(x+1, y); err == nil {
+			s.CostRight = nr.Cost
+		}
+	}
+	if !s.Bottom {
+		if nr, err := m.GetRoom(x, y+1); err == nil {
+			s.CostBottom = nr.Cost
+		}
+	}
+	if !s.Left {
+		if nr, err := m.GetRoom(x-1, y); err == nil {
+			s.CostLeft = nr.Cost
+		}
+	}
+
+	return s, nil
+}
+
+// Moves Icarus's position left one step
+// Will not permit moving through walls or out of the maze
+func (m *Maze) MoveLeft() error {
+	s, e := m.LookAround()
+	if e != nil {
+		return e
+	}
+	if s.Left {
+		return errors.New("Can't walk through walls")
+	}
+
+	x, y := m.Icarus()
+	room, err := m.GetRoom(x-1, y)
+	if err != nil {
+		return err
+	}
+
+	m.icarus = Coordinate{x - 1, y}
+	m.teleport()
+	m.StepsTaken++
+	m.Energy += room.Cost
+	return nil
+}
+
+// Moves Icarus's position right one step
+// Will not permit moving through walls or out of the maze
+func (m *Maze) MoveRight() error {
+	s, e := m.LookAround()
+	if e != nil {
+		return e
+	}
+	if s.Right {
+		return errors.New("Can't walk through walls")
+	}
+
+	x, y := m.Icarus()
+	room, err := m.GetRoom(x+1, y)
+	if err != nil {
+		return err
+	}
+
+	m.icarus = Coordinate{x + 1, y}
+	m.teleport()
+	m.StepsTaken++
+	m.Energy += room.Cost
+	return nil
+}
+
+// Moves Icarus's position up one step
+// Will not permit moving through walls or out of the maze
+func (m *Maze) MoveUp() error {
+	s, e := m.LookAround()
+	if e != nil {
+		return e
+	}
+	if s.Top {
+		return errors.New("Can't walk through walls")
+	}
+
+	x, y := m.Icarus()
+	room, err := m.GetRoom(x, y-1)
+	if err != nil {
+		return err
+	}
+
+	m.icarus = Coordinate{x, y - 1}
+	m.teleport()
+	m.StepsTaken++
+	m.Energy += room.Cost
+	return nil
+}
+
+// Moves Icarus's position down one step
+// Will not permit moving through walls or out of the maze
+func (m *Maze) MoveDown() error {
+	s, e := m.LookAround()
+	if e != nil {
+		return e
+	}
+	if s.Bottom {
+		return errors.New("Can't walk through walls")
+	}
+
+	x, y := m.Icarus()
+	room, err := m.GetRoom(x, y+1)
+	if err != nil {
+		return err
+	}
+
+	m.icarus = Coordinate{x, y + 1}
+	m.teleport()
+	m.StepsTaken++
+	m.Energy += room.Cost
+	return nil
+}
@@ -0,0 +1,143 @@
+// Copyright © 2015 Steve Francia <spf@spf13.com>.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+//
+
+// Package mazelib holds the types shared between Daedalus (the maze server)
+// and Icarus (the maze solver) so that neither needs to know about the
+// other's internals.
+package mazelib
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrVictory is returned by Maze.LookAround once Icarus has reached
+// the treasure.
+var ErrVictory = errors.New("Victory")
+
+// Coordinate is a simple x,y pair used throughout the maze and survey types.
+type Coordinate struct {
+	X int
+	Y int
+}
+
+// Survey describes what Icarus can see from his current room: whether there
+// is a wall blocking each of the four directions, and - for weighted mazes -
+// the energy cost of stepping into the room that lies past an open wall.
+type Survey struct {
+	Top    bool
+	Right  bool
+	Bottom bool
+	Left   bool
+
+	CostTop    int `json:",omitempty"`
+	CostRight  int `json:",omitempty"`
+	CostBottom int `json:",omitempty"`
+	CostLeft   int `json:",omitempty"`
+}
+
+// Room is a single cell of the maze. Cost is only meaningful for weighted
+// mazes (e.g. the "weighted" generator); it's the energy Icarus spends entering it.
+type Room struct {
+	Treasure bool
+	Start    bool
+	Visited  bool
+	POI      bool
+	Walls    Survey
+	Cost     int
+}
+
+// MazeI is the interface Daedalus's Maze type satisfies, kept here so that
+// helpers like PrintMaze don't need to import the commands package.
+type MazeI interface {
+	Width() int
+	Height() int
+	Icarus() (x, y int)
+	GetRoom(x, y int) (*Room, error)
+}
+
+// Portal is a pair of rooms that teleport Icarus between each other on
+// entry, e.g. for the "portal" generator.
+type Portal struct {
+	Name string
+	A    Coordinate
+	B    Coordinate
+}
+
+// Reply is the JSON body Daedalus sends back for /awake and /move/:direction.
+type Reply struct {
+	Survey     Survey
+	Victory    bool
+	Message    string
+	Error      bool
+	Teleported *Portal `json:",omitempty"`
+	// POI reports whether the room Icarus is now standing in is a waypoint
+	// registered with Maze.AddPOI, so a multi-stop IA knows to add it to its
+	// tour.
+	POI bool `json:",omitempty"`
+	// SessionID is only set on the /awake reply, identifying the maze this
+	// client is now attached to. Pass it back via the "session" cookie or
+	// the X-Session-Id header on every later call.
+	SessionID string `json:",omitempty"`
+
+	// StepsTaken and Energy mirror the matching fields on Maze, so a client
+	// doesn't have to keep its own tally to see how it's doing on a
+	// weighted maze.
+	StepsTaken int `json:",omitempty"`
+	Energy     int `json:",omitempty"`
+}
+
+// PrintMaze writes a quick ascii dump of the maze to stdout, mostly useful
+// while developing a new generator.
+func PrintMaze(m MazeI) {
+	for y := 0; y < m.Height(); y++ {
+		for x := 0; x < m.Width(); x++ {
+			room, err := m.GetRoom(x, y)
+			if err != nil {
+				fmt.Print("?")
+				continue
+			}
+			ix, iy := m.Icarus()
+			switch {
+			case ix == x && iy == y:
+				fmt.Print("I")
+			case room.Treasure:
+				fmt.Print("T")
+			case room.Start:
+				fmt.Print("S")
+			case room.POI:
+				fmt.Print("P")
+			case room.Visited:
+				fmt.Print(".")
+			default:
+				fmt.Print(" ")
+			}
+		}
+		fmt.Println()
+	}
+}
+
+// AvgScores returns the average of a slice of scores, 0 if the slice is empty.
+func AvgScores(s []int) int {
+	if len(s) == 0 {
+		return 0
+	}
+
+	total := 0
+	for _, v := range s {
+		total += v
+	}
+	return total / len(s)
+}
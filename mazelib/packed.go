@@ -0,0 +1,193 @@
+// Copyright © 2015 Steve Francia <spf@spf13.com>.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+//
+
+package mazelib
+
+import "errors"
+
+// bitset is a flat array of single bits, used by PackedMaze for anything
+// that's naturally a per-cell boolean (Visited, Start, Treasure).
+type bitset []byte
+
+func newBitset(n int) bitset { return make(bitset, (n+7)/8) }
+
+func (b bitset) get(i int) bool { return b[i/8]&(1<<uint(i%8)) != 0 }
+
+func (b bitset) set(i int, v bool) {
+	if v {
+		b[i/8] |= 1 << uint(i%8)
+	} else {
+		b[i/8] &^= 1 << uint(i%8)
+	}
+}
+
+// PackedMaze is a bit-packed alternative to Maze for grids too large to
+// afford a full Room struct per cell - a plain [][]Room grid costs 56
+// bytes/room on a 64-bit build, which is over 5 gigabytes at 10k x 10k.
+//
+// Each cell only stores its own Right and Bottom walls (2 bits); Top and
+// Left are synthesized by reading the neighbor's Bottom/Right, since that's
+// the same wall seen from the other side. Visited/Start/Treasure are
+// parallel 1-bit-per-cell bitsets. GetRoom still returns a normal *Room,
+// synthesized on demand, so PackedMaze is a drop-in MazeI wherever only
+// read access is needed.
+type PackedMaze struct {
+	width, height int
+
+	walls    bitset // 2 bits/cell: bit 0 = Right wall, bit 1 = Bottom wall
+	visited  bitset
+	start    bitset
+	treasure bitset
+
+	icarus Coordinate
+	end    Coordinate
+}
+
+// NewPackedMaze creates a packed maze without any walls.
+func NewPackedMaze(width, height int) *PackedMaze {
+	cells := width * height
+	return &PackedMaze{
+		width:    width,
+		height:   height,
+		walls:    newBitset(cells * 2),
+		visited:  newBitset(cells),
+		start:    newBitset(cells),
+		treasure: newBitset(cells),
+	}
+}
+
+// NewFullPackedMaze creates a packed maze with every wall up. Good starting
+// point for a subtractive algorithm like carveBacktracker.
+func NewFullPackedMaze(width, height int) *PackedMaze {
+	m := NewPackedMaze(width, height)
+	for i := range m.walls {
+		m.walls[i] = 0xff
+	}
+	return m
+}
+
+func (m *PackedMaze) cellIndex(x, y int) int { return y*m.width + x }
+
+func (m *PackedMaze) Width() int  { return m.width }
+func (m *PackedMaze) Height() int { return m.height }
+
+// Icarus returns Icarus's current position.
+func (m *PackedMaze) Icarus() (x, y int) { return m.icarus.X, m.icarus.Y }
+
+func (m *PackedMaze) hasRight(x, y int) bool {
+	if x == m.width-1 {
+		return true
+	}
+	return m.walls.get(m.cellIndex(x, y) * 2)
+}
+
+func (m *PackedMaze) hasBottom(x, y int) bool {
+	if y == m.height-1 {
+		return true
+	}
+	return m.walls.get(m.cellIndex(x, y)*2 + 1)
+}
+
+func (m *PackedMaze) hasLeft(x, y int) bool {
+	if x == 0 {
+		return true
+	}
+	return m.hasRight(x-1, y)
+}
+
+func (m *PackedMaze) hasTop(x, y int) bool {
+	if y == 0 {
+		return true
+	}
+	return m.hasBottom(x, y-1)
+}
+
+// GetRoom synthesizes a *Room for (x, y) from the packed bits.
+func (m *PackedMaze) GetRoom(x, y int) (*Room, error) {
+	if x < 0 || y < 0 || x >= m.width || y >= m.height {
+		return &Room{}, errors.New("room outside of maze boundaries")
+	}
+
+	idx := m.cellIndex(x, y)
+	return &Room{
+		Treasure: m.treasure.get(idx),
+		Start:    m.start.get(idx),
+		Visited:  m.visited.get(idx),
+		Walls: Survey{
+			Top:    m.hasTop(x, y),
+			Right:  m.hasRight(x, y),
+			Bottom: m.hasBottom(x, y),
+			Left:   m.hasLeft(x, y),
+		},
+	}, nil
+}
+
+// Visited reports whether (x, y) has been marked visited.
+func (m *PackedMaze) Visited(x, y int) bool { return m.visited.get(m.cellIndex(x, y)) }
+
+// SetVisited marks (x, y) visited.
+func (m *PackedMaze) SetVisited(x, y int) { m.visited.set(m.cellIndex(x, y), true) }
+
+// OpenTop opens the wall between (x, y) and its northern neighbor.
+func (m *PackedMaze) OpenTop(x, y int) {
+	if y > 0 {
+		m.walls.set(m.cellIndex(x, y-1)*2+1, false)
+	}
+}
+
+// OpenBottom opens the wall between (x, y) and its southern neighbor.
+func (m *PackedMaze) OpenBottom(x, y int) {
+	if y < m.height-1 {
+		m.walls.set(m.cellIndex(x, y)*2+1, false)
+	}
+}
+
+// OpenLeft opens the wall between (x, y) and its western neighbor.
+func (m *PackedMaze) OpenLeft(x, y int) {
+	if x > 0 {
+		m.walls.set(m.cellIndex(x-1, y)*2, false)
+	}
+}
+
+// OpenRight opens the wall between (x, y) and its eastern neighbor.
+func (m *PackedMaze) OpenRight(x, y int) {
+	if x < m.width-1 {
+		m.walls.set(m.cellIndex(x, y)*2, false)
+	}
+}
+
+// SetStartPoint sets the location where Icarus will awake.
+func (m *PackedMaze) SetStartPoint(x, y int) error {
+	idx := m.cellIndex(x, y)
+	if m.treasure.get(idx) {
+		return errors.New("can't start in the treasure")
+	}
+
+	m.start.set(idx, true)
+	m.icarus = Coordinate{X: x, Y: y}
+	return nil
+}
+
+// SetTreasure sets the location of the treasure.
+func (m *PackedMaze) SetTreasure(x, y int) error {
+	idx := m.cellIndex(x, y)
+	if m.start.get(idx) {
+		return errors.New("can't have the treasure at the start")
+	}
+
+	m.treasure.set(idx, true)
+	m.end = Coordinate{X: x, Y: y}
+	return nil
+}
@@ -0,0 +1,202 @@
+// Copyright © 2015 Steve Francia <spf@spf13.com>.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+//
+
+package mazelib
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// LoadMazeText and SaveMazeText read/write a maze as a plain ASCII grid, so
+// a maze can be hand-crafted in a text editor or checked into a repo as a
+// fixture. Each room occupies a 2x2 block: its center cell, plus the wall
+// column to its right and the wall row below it, closed off by one extra
+// row/column along the top and left edge of the grid. That makes every
+// one of the four Room.Walls booleans land on its own character, so the
+// format round-trips losslessly:
+//
+//	#####
+//	#S  #
+//	# # #
+//	#  T#
+//	#####
+//
+// '#' is a wall, ' ' is open floor, 'S' marks the start room and 'T' the
+// treasure.
+const (
+	wallChar     = '#'
+	openChar     = ' '
+	startChar    = 'S'
+	treasureChar = 'T'
+)
+
+// LoadMazeText parses the ASCII grid format written by SaveMazeText.
+func LoadMazeText(r io.Reader) (*Maze, error) {
+	grid, startX, startY, treasureX, treasureY, err := parseRoomGrid(r, openChar, wallChar)
+	if err != nil {
+		return nil, err
+	}
+
+	if startX == -1 {
+		return nil, errors.New("mazelib: text maze is missing a start room ('S')")
+	}
+	if treasureX == -1 {
+		return nil, errors.New("mazelib: text maze is missing a treasure room ('T')")
+	}
+
+	m := NewMazeFromRooms(grid)
+	if err := m.SetStartPoint(startX, startY); err != nil {
+		return nil, err
+	}
+	if err := m.SetTreasure(treasureX, treasureY); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// parseRoomGrid does the parsing behind LoadMazeText (LoadMazeFromText uses
+// parseCellGrid instead): it reads the 2x2-block ASCII grid and converts it into
+// a [][]Room, along with the coordinates of the 'S'/'T' markers if either
+// was present (-1, -1 otherwise, left for the caller to decide what to do
+// about).
+func parseRoomGrid(r io.Reader, pathChar, wallChar byte) (grid [][]Room, startX, startY, treasureX, treasureY int, err error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	var lines []string
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, 0, 0, 0, 0, err
+	}
+
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	if len(lines) < 3 || len(lines)%2 == 0 {
+		return nil, 0, 0, 0, 0, fmt.Errorf("mazelib: text maze must have an odd number of rows >= 3, got %d", len(lines))
+	}
+
+	cols := 0
+	for _, l := range lines {
+		if len(l) > cols {
+			cols = len(l)
+		}
+	}
+	if cols < 3 || cols%2 == 0 {
+		return nil, 0, 0, 0, 0, fmt.Errorf("mazelib: text maze must have an odd width >= 3, got %d", cols)
+	}
+
+	height := (len(lines) - 1) / 2
+	width := (cols - 1) / 2
+
+	at := func(row, col int) byte {
+		if row < 0 || row >= len(lines) {
+			return pathChar
+		}
+		line := lines[row]
+		if col < 0 || col >= len(line) {
+			return pathChar
+		}
+		return line[col]
+	}
+
+	grid = make([][]Room, height)
+	for y := range grid {
+		grid[y] = make([]Room, width)
+	}
+
+	startX, startY, treasureX, treasureY = -1, -1, -1, -1
+
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			room := &grid[y][x]
+			room.Walls.Top = at(2*y, 2*x+1) == wallChar
+			room.Walls.Left = at(2*y+1, 2*x) == wallChar
+			room.Walls.Bottom = at(2*y+2, 2*x+1) == wallChar
+			room.Walls.Right = at(2*y+1, 2*x+2) == wallChar
+
+			switch at(2*y+1, 2*x+1) {
+			case startChar:
+				startX, startY = x, y
+			case treasureChar:
+				treasureX, treasureY = x, y
+			}
+		}
+	}
+
+	return grid, startX, startY, treasureX, treasureY, nil
+}
+
+// SaveMazeText writes m in the ASCII grid format LoadMazeText reads back.
+func SaveMazeText(w io.Writer, m *Maze) error {
+	width, height := m.Width(), m.Height()
+	rows, cols := 2*height+1, 2*width+1
+
+	grid := make([][]byte, rows)
+	for i := range grid {
+		grid[i] = make([]byte, cols)
+		for j := range grid[i] {
+			grid[i][j] = openChar
+		}
+	}
+
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			room, err := m.GetRoom(x, y)
+			if err != nil {
+				return err
+			}
+
+			if room.Walls.Top {
+				grid[2*y][2*x+1] = wallChar
+			}
+			if room.Walls.Left {
+				grid[2*y+1][2*x] = wallChar
+			}
+			if room.Walls.Bottom {
+				grid[2*y+2][2*x+1] = wallChar
+			}
+			if room.Walls.Right {
+				grid[2*y+1][2*x+2] = wallChar
+			}
+
+			switch {
+			case room.Start:
+				grid[2*y+1][2*x+1] = startChar
+			case room.Treasure:
+				grid[2*y+1][2*x+1] = treasureChar
+			}
+		}
+	}
+
+	bw := bufio.NewWriter(w)
+	for _, row := range grid {
+		if _, err := bw.Write(row); err != nil {
+			return err
+		}
+		if err := bw.WriteByte('\n'); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
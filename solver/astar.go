@@ -0,0 +1,108 @@
+// Copyright © 2015 Steve Francia <spf@spf13.com>.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+//
+
+package solver
+
+import (
+	"container/heap"
+
+	"github.com/golangchallenge/gc6/mazelib"
+)
+
+func init() {
+	Register(aStarSolver{})
+}
+
+// aStarSolver is Dijkstra with a Manhattan-distance heuristic toward the
+// treasure, so it expands fewer rooms than dijkstraSolver on the same maze -
+// that gap is itself a useful difficulty signal between generators.
+type aStarSolver struct{}
+
+func (aStarSolver) Name() string { return "astar" }
+
+type aStarItem struct {
+	coord mazelib.Coordinate
+	g     int // cost from start
+	f     int // g plus the heuristic to the goal
+}
+
+type aStarQueue []aStarItem
+
+func (h aStarQueue) Len() int            { return len(h) }
+func (h aStarQueue) Less(i, j int) bool  { return h[i].f < h[j].f }
+func (h aStarQueue) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *aStarQueue) Push(x interface{}) { *h = append(*h, x.(aStarItem)) }
+func (h *aStarQueue) Pop() interface{} {
+	old := *h
+	n := len(old)
+	item := old[n-1]
+	*h = old[:n-1]
+	return item
+}
+
+// manhattan is an admissible heuristic here since Icarus can only move in
+// the four cardinal directions and every step costs at least 1.
+func manhattan(a, b mazelib.Coordinate) int {
+	dx := a.X - b.X
+	if dx < 0 {
+		dx = -dx
+	}
+	dy := a.Y - b.Y
+	if dy < 0 {
+		dy = -dy
+	}
+	return dx + dy
+}
+
+func (aStarSolver) Solve(m mazelib.MazeI) ([]mazelib.Coordinate, int) {
+	sx, sy := m.Icarus()
+	start := mazelib.Coordinate{X: sx, Y: sy}
+
+	goal, ok := findTreasure(m)
+	if !ok {
+		return nil, 0
+	}
+
+	gScore := map[mazelib.Coordinate]int{start: 0}
+	cameFrom := map[mazelib.Coordinate]mazelib.Coordinate{}
+	frontier := &aStarQueue{{coord: start, g: 0, f: manhattan(start, goal)}}
+	heap.Init(frontier)
+	expanded := 0
+
+	for frontier.Len() > 0 {
+		cur := heap.Pop(frontier).(aStarItem)
+		expanded++
+
+		if cur.coord == goal {
+			return reconstructPath(cameFrom, start, goal), expanded
+		}
+
+		if best, ok := gScore[cur.coord]; ok && cur.g > best {
+			continue
+		}
+
+		for _, n := range neighbors(m, cur.coord) {
+			next := cur.g + roomCost(m, n)
+			if best, ok := gScore[n]; ok && best <= next {
+				continue
+			}
+			gScore[n] = next
+			cameFrom[n] = cur.coord
+			heap.Push(frontier, aStarItem{coord: n, g: next, f: next + manhattan(n, goal)})
+		}
+	}
+
+	return nil, expanded
+}
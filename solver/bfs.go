@@ -0,0 +1,65 @@
+// Copyright © 2015 Steve Francia <spf@spf13.com>.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+//
+
+package solver
+
+import "github.com/golangchallenge/gc6/mazelib"
+
+func init() {
+	Register(bfsSolver{})
+}
+
+// bfsSolver is a plain breadth-first search. It ignores Room.Cost entirely,
+// so it finds the shortest path by step count - the right metric for a
+// maze that doesn't use the weighted generator.
+type bfsSolver struct{}
+
+func (bfsSolver) Name() string { return "bfs" }
+
+func (bfsSolver) Solve(m mazelib.MazeI) ([]mazelib.Coordinate, int) {
+	sx, sy := m.Icarus()
+	start := mazelib.Coordinate{X: sx, Y: sy}
+
+	goal, ok := findTreasure(m)
+	if !ok {
+		return nil, 0
+	}
+
+	visited := map[mazelib.Coordinate]bool{start: true}
+	cameFrom := map[mazelib.Coordinate]mazelib.Coordinate{}
+	queue := []mazelib.Coordinate{start}
+	expanded := 0
+
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+		expanded++
+
+		if cur == goal {
+			return reconstructPath(cameFrom, start, goal), expanded
+		}
+
+		for _, n := range neighbors(m, cur) {
+			if visited[n] {
+				continue
+			}
+			visited[n] = true
+			cameFrom[n] = cur
+			queue = append(queue, n)
+		}
+	}
+
+	return nil, expanded
+}
@@ -0,0 +1,103 @@
+// Copyright © 2015 Steve Francia <spf@spf13.com>.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+//
+
+package solver
+
+import (
+	"container/heap"
+
+	"github.com/golangchallenge/gc6/mazelib"
+)
+
+func init() {
+	Register(dijkstraSolver{})
+}
+
+// dijkstraSolver finds the cheapest path by Room.Cost, same as
+// generators.dijkstraOptimalCost computes for Maze.Optimal, but returning
+// the path itself rather than just its cost. Rooms with no Cost set (the
+// zero value) are treated as costing 1, so it degrades to plain BFS-by-cost
+// on a maze that was never given weights.
+type dijkstraSolver struct{}
+
+func (dijkstraSolver) Name() string { return "dijkstra" }
+
+type costItem struct {
+	coord mazelib.Coordinate
+	cost  int
+}
+
+type costQueue []costItem
+
+func (h costQueue) Len() int            { return len(h) }
+func (h costQueue) Less(i, j int) bool  { return h[i].cost < h[j].cost }
+func (h costQueue) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *costQueue) Push(x interface{}) { *h = append(*h, x.(costItem)) }
+func (h *costQueue) Pop() interface{} {
+	old := *h
+	n := len(old)
+	item := old[n-1]
+	*h = old[:n-1]
+	return item
+}
+
+func roomCost(m mazelib.MazeI, c mazelib.Coordinate) int {
+	room, err := m.GetRoom(c.X, c.Y)
+	if err != nil || room.Cost == 0 {
+		return 1
+	}
+	return room.Cost
+}
+
+func (dijkstraSolver) Solve(m mazelib.MazeI) ([]mazelib.Coordinate, int) {
+	sx, sy := m.Icarus()
+	start := mazelib.Coordinate{X: sx, Y: sy}
+
+	goal, ok := findTreasure(m)
+	if !ok {
+		return nil, 0
+	}
+
+	dist := map[mazelib.Coordinate]int{start: 0}
+	cameFrom := map[mazelib.Coordinate]mazelib.Coordinate{}
+	frontier := &costQueue{{coord: start, cost: 0}}
+	heap.Init(frontier)
+	expanded := 0
+
+	for frontier.Len() > 0 {
+		cur := heap.Pop(frontier).(costItem)
+		expanded++
+
+		if cur.coord == goal {
+			return reconstructPath(cameFrom, start, goal), expanded
+		}
+
+		if best, ok := dist[cur.coord]; ok && cur.cost > best {
+			continue
+		}
+
+		for _, n := range neighbors(m, cur.coord) {
+			next := cur.cost + roomCost(m, n)
+			if best, ok := dist[n]; ok && best <= next {
+				continue
+			}
+			dist[n] = next
+			cameFrom[n] = cur.coord
+			heap.Push(frontier, costItem{coord: n, cost: next})
+		}
+	}
+
+	return nil, expanded
+}
@@ -0,0 +1,105 @@
+// Copyright © 2015 Steve Francia <spf@spf13.com>.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+//
+
+// Package solver treats any mazelib.MazeI as a graph and finds the shortest
+// path from Icarus's current room to the treasure. Unlike Icarus's own
+// move-by-move strategies, a Solver here sees the whole maze at once - it
+// exists to let Daedalus validate that a generator actually produced a
+// solvable maze, and to score a generator's difficulty by how many nodes a
+// search has to expand to find the way out.
+package solver
+
+import "github.com/golangchallenge/gc6/mazelib"
+
+// Solver finds the shortest path from m's current Icarus position to its
+// treasure. path is nil if no such path exists. expanded is the number of
+// rooms the search visited, a rough proxy for how hard the maze is to solve.
+type Solver interface {
+	Name() string
+	Solve(m mazelib.MazeI) (path []mazelib.Coordinate, expanded int)
+}
+
+// registry is the set of solvers registered via Register, keyed by Name().
+var registry = map[string]Solver{}
+
+// Register adds s to the registry under s.Name(), so it can be looked up by
+// name later (e.g. from a --solve flag). Meant to be called from init().
+func Register(s Solver) {
+	registry[s.Name()] = s
+}
+
+// Get looks up a solver previously added with Register.
+func Get(name string) (Solver, bool) {
+	s, ok := registry[name]
+	return s, ok
+}
+
+// neighbors returns the rooms reachable from c in a single step, i.e. every
+// direction out of c's room whose wall is down.
+func neighbors(m mazelib.MazeI, c mazelib.Coordinate) []mazelib.Coordinate {
+	room, err := m.GetRoom(c.X, c.Y)
+	if err != nil {
+		return nil
+	}
+
+	var out []mazelib.Coordinate
+	if !room.Walls.Top {
+		out = append(out, mazelib.Coordinate{X: c.X, Y: c.Y - 1})
+	}
+	if !room.Walls.Right {
+		out = append(out, mazelib.Coordinate{X: c.X + 1, Y: c.Y})
+	}
+	if !room.Walls.Bottom {
+		out = append(out, mazelib.Coordinate{X: c.X, Y: c.Y + 1})
+	}
+	if !room.Walls.Left {
+		out = append(out, mazelib.Coordinate{X: c.X - 1, Y: c.Y})
+	}
+	return out
+}
+
+// findTreasure scans every room in m for the treasure, since MazeI doesn't
+// expose its coordinates directly (only Maze does, and a Solver should work
+// against any MazeI, including mazelib.PackedMaze).
+func findTreasure(m mazelib.MazeI) (mazelib.Coordinate, bool) {
+	for y := 0; y < m.Height(); y++ {
+		for x := 0; x < m.Width(); x++ {
+			room, err := m.GetRoom(x, y)
+			if err == nil && room.Treasure {
+				return mazelib.Coordinate{X: x, Y: y}, true
+			}
+		}
+	}
+	return mazelib.Coordinate{}, false
+}
+
+// reconstructPath walks cameFrom backwards from goal to start and returns
+// the room-by-room path in forward order, start first.
+func reconstructPath(cameFrom map[mazelib.Coordinate]mazelib.Coordinate, start, goal mazelib.Coordinate) []mazelib.Coordinate {
+	path := []mazelib.Coordinate{goal}
+	for cur := goal; cur != start; {
+		prev, ok := cameFrom[cur]
+		if !ok {
+			return nil
+		}
+		path = append(path, prev)
+		cur = prev
+	}
+
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+	return path
+}